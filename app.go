@@ -6,7 +6,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/BurntSushi/toml"
 	ui "github.com/gizak/termui/v3"
 	"github.com/go-redis/redis/v8"
 	"github.com/milonoir/rv/common"
@@ -15,64 +14,77 @@ import (
 	"github.com/milonoir/rv/scanner"
 )
 
-const (
-	scannerUsage = `  [<Up>](fg:yellow)/[<Down>](fg:yellow)   move selection up/down   [<Enter>](fg:yellow) select            [<m>](fg:yellow) view messages
-[<PgUp>](fg:yellow)/[<PgDown>](fg:yellow) scroll up/down           [<e>](fg:yellow)     enable scanner
-[<Home>](fg:yellow)/[<End>](fg:yellow)    move to top/bottom       [<d>](fg:yellow)     disable scanner   [<q>](fg:yellow) quit`
-	selectorUsage = `  [<Up>](fg:yellow)/[<Down>](fg:yellow)   move selection up/down   [<Enter>](fg:yellow) select
-[<PgUp>](fg:yellow)/[<PgDown>](fg:yellow) scroll up/down           [<Esc>](fg:yellow)   go back
-[<Home>](fg:yellow)/[<End>](fg:yellow)    move to top/bottom       [<q>](fg:yellow)     quit`
-	viewerUsage = `  [<Up>](fg:yellow)/[<Down>](fg:yellow)   move selection up/down
-[<PgUp>](fg:yellow)/[<PgDown>](fg:yellow) scroll up/down           [<Esc>](fg:yellow)   go back
-[<Home>](fg:yellow)/[<End>](fg:yellow)    move to top/bottom       [<q>](fg:yellow)     quit`
-	messagesUsage = `[<Esc>](fg:yellow) go back
-  [<q>](fg:yellow) quit`
-)
-
 var (
-	updateInterval = 100 * time.Millisecond
-	viewerTimeout  = 3 * time.Second
-)
+	updateInterval  = 100 * time.Millisecond
+	viewerTimeout   = 3 * time.Second
+	defaultInterval = 30 * time.Second
 
-// config represents the application configuration.
-type config struct {
-	Redis *r.Config
-	Scans map[string]*scanner.Config
-}
+	scannerFields = []string{"Name", "Pattern", "Type", "Interval"}
+	oneShotFields = []string{"Pattern", "Type"}
+)
 
 // app represents the main application.
 type app struct {
-	cfg *config
-	rc  *redis.Client
+	cfg     *config
+	profile string
+	rc      redis.UniversalClient
+	rcLog   *r.Logger
 
 	scanner  scanner.Scanner
 	selector scanner.Selector
+	tree     scanner.Tree
 	viewer   scanner.Viewer
 	helper   common.TextBox
 	messages common.TextBox
+	keys     common.TextBox
+	prompt   common.Prompt
+	search   common.Prompt
+	form     common.Form
+	progress common.TextBox
+	chart    scanner.Chart
+	profiles common.Picker
 	logger   logger.Logger
 
+	// oneShot is the one-shot scan in progress, if any.
+	oneShot scanner.OneShot
+
 	messagesVisible bool
+	keysVisible     bool
+	promptVisible   bool
+	formVisible     bool
+	chartVisible    bool
 	selectorVisible bool
 	viewerVisible   bool
+	profilesVisible bool
+	searchVisible   bool
+
+	// treeMode is true if the keys are shown in the keyspace tree instead of the selector.
+	treeMode bool
+	// sortMode is the order of the scanners and the keys by name.
+	sortMode common.SortMode
+	// searchMode is the way the search query of the selector matches the keys.
+	searchMode scanner.SearchMode
+
+	// promptSubmit is called with the input of the prompt when it is submitted.
+	promptSubmit func(string)
+	// formSubmit is called with the values of the form when it is submitted. The form is closed if it
+	// returns true.
+	formSubmit func([]string) bool
 
 	msgCh chan string
 }
 
 // newApp creates and configures a new app.
 func newApp(cfgFile string) (*app, error) {
-	f, err := common.LoadFile(cfgFile)
+	cfg, err := loadConfig(cfgFile)
 	if err != nil {
-		return nil, fmt.Errorf("load config file: %w", err)
-	}
-
-	cfg := &config{}
-	if _, err = toml.Decode(string(f), cfg); err != nil {
-		return nil, fmt.Errorf("parse toml config: %w", err)
+		return nil, err
 	}
 
 	return &app{
-		cfg: cfg,
+		cfg:      cfg,
+		profile:  cfg.Profile,
+		sortMode: cfg.Sort,
 	}, nil
 }
 
@@ -89,29 +101,97 @@ func (a *app) setup() error {
 	return nil
 }
 
-// setupRedis configures the Redis client and tests its connection to the Redis server.
+// setupRedis configures the Redis client of the active profile and tests its connection to the Redis server.
 func (a *app) setupRedis() error {
-	a.rc = redis.NewClient(&redis.Options{
-		Addr:         a.cfg.Redis.Server,
-		Password:     a.cfg.Redis.Password,
-		DB:           a.cfg.Redis.DB,
-		DialTimeout:  a.cfg.Redis.DialTimeout.Duration,
-		IdleTimeout:  a.cfg.Redis.IdleTimeout.Duration,
-		ReadTimeout:  a.cfg.Redis.ReadTimeout.Duration,
-		WriteTimeout: a.cfg.Redis.WriteTimeout.Duration,
-		MaxRetries:   a.cfg.Redis.MaxRetries,
-	})
+	// Redis client logs (e.g. failover events) are sent to the logger widget instead of stderr.
+	a.rcLog = r.NewLogger()
+	redis.SetLogger(a.rcLog)
+
+	rc, err := connect(&a.cfg.profiles[a.profile].Config)
+	if err != nil {
+		return fmt.Errorf("profile %s: %w", a.profile, err)
+	}
+	a.rc = rc
+
+	return nil
+}
+
+// connect returns a Redis client configured by cfg after testing its connection to the Redis server.
+func connect(cfg *r.Config) (redis.UniversalClient, error) {
+	tlsCfg, err := cfg.TLS.Build()
+	if err != nil {
+		return nil, fmt.Errorf("configure TLS: %w", err)
+	}
+
+	username, err := cfg.Username.Resolve()
+	if err != nil {
+		return nil, fmt.Errorf("load username: %w", err)
+	}
+	password, err := cfg.Password.Resolve()
+	if err != nil {
+		return nil, fmt.Errorf("load password: %w", err)
+	}
+
+	var rc redis.UniversalClient
+	switch {
+	case cfg.Sentinel != nil:
+		sentinelPassword, err := cfg.Sentinel.Password.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("load sentinel password: %w", err)
+		}
+		rc = redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       cfg.Sentinel.MasterName,
+			SentinelAddrs:    cfg.Sentinel.Addrs,
+			SentinelPassword: sentinelPassword,
+			Username:         username,
+			Password:         password,
+			DB:               cfg.DB,
+			DialTimeout:      cfg.DialTimeout.Duration,
+			IdleTimeout:      cfg.IdleTimeout.Duration,
+			ReadTimeout:      cfg.ReadTimeout.Duration,
+			WriteTimeout:     cfg.WriteTimeout.Duration,
+			MaxRetries:       cfg.MaxRetries,
+			TLSConfig:        tlsCfg,
+		})
+	case cfg.Cluster:
+		rc = redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        cfg.ClusterAddrs(),
+			Username:     username,
+			Password:     password,
+			DialTimeout:  cfg.DialTimeout.Duration,
+			IdleTimeout:  cfg.IdleTimeout.Duration,
+			ReadTimeout:  cfg.ReadTimeout.Duration,
+			WriteTimeout: cfg.WriteTimeout.Duration,
+			MaxRetries:   cfg.MaxRetries,
+			TLSConfig:    tlsCfg,
+		})
+	default:
+		rc = redis.NewClient(&redis.Options{
+			Addr:         cfg.Server,
+			Username:     username,
+			Password:     password,
+			DB:           cfg.DB,
+			DialTimeout:  cfg.DialTimeout.Duration,
+			IdleTimeout:  cfg.IdleTimeout.Duration,
+			ReadTimeout:  cfg.ReadTimeout.Duration,
+			WriteTimeout: cfg.WriteTimeout.Duration,
+			MaxRetries:   cfg.MaxRetries,
+			TLSConfig:    tlsCfg,
+		})
+	}
 
 	// Test connection.
-	reply, err := a.rc.Do(context.Background(), "PING").Text()
+	reply, err := rc.Do(context.Background(), "PING").Text()
 	if err != nil {
-		return fmt.Errorf("test Redis connection ping: %w", err)
+		rc.Close()
+		return nil, fmt.Errorf("test Redis connection ping: %w", err)
 	}
 	if reply != "PONG" {
-		return fmt.Errorf("unexpected response from Redis: %s != PONG", reply)
+		rc.Close()
+		return nil, fmt.Errorf("unexpected response from Redis: %s != PONG", reply)
 	}
 
-	return nil
+	return rc, nil
 }
 
 // initUI initializes the termui.
@@ -124,24 +204,48 @@ func (a *app) initWidgets(ctx context.Context) {
 	a.msgCh = make(chan string, 1)
 
 	// Scanner widget
-	a.scanner = scanner.NewScanner(ctx, a.rc, a.cfg.Scans)
+	a.scanner = scanner.NewScanner(ctx, a.rc, a.cfg.profiles[a.profile].Scans, a.profileLabel(), a.sortMode)
 
 	// Selector widget
-	a.selector = scanner.NewSelector()
+	a.selector = scanner.NewSelector(ctx, a.rc, a.sortMode)
+
+	// Tree widget
+	a.tree = scanner.NewTree(a.cfg.Delimiter, a.sortMode)
 
 	// Viewer widget
-	a.viewer = scanner.NewViewer(a.rc)
+	a.viewer = scanner.NewViewer(ctx, a.rc, a.cfg.decoders, a.cfg.WatchInterval.Duration)
 
 	// Helper widget
 	a.helper = common.NewTextBox(" Help ")
 	a.helper.SetText(scannerUsage)
 
 	// Logger widget
-	a.logger = logger.NewLogger(ctx, a.msgCh, a.scanner.Messages(), a.viewer.Messages())
+	a.logger = logger.NewLogger(ctx, a.msgCh, a.rcLog.Messages(), a.scanner.Messages(), a.viewer.Messages())
 
 	// Messages widget
 	a.messages = common.NewTextBox(" Messages ")
 
+	// Keys widget
+	a.keys = common.NewTextBox(" Keys ")
+
+	// Prompt widget
+	a.prompt = common.NewPrompt()
+
+	// Search widget
+	a.search = common.NewPrompt()
+
+	// Form widget
+	a.form = common.NewForm()
+
+	// One-shot scan progress widget
+	a.progress = common.NewTextBox(" Scanning ")
+
+	// Chart widget
+	a.chart = scanner.NewChart()
+
+	// Profiles widget
+	a.profiles = common.NewPicker(" Select a profile ")
+
 	a.resize(ui.TerminalDimensions())
 }
 
@@ -165,27 +269,44 @@ func (a *app) run() {
 			case "<Resize>":
 				payload := e.Payload.(ui.Resize)
 				a.resize(payload.Width, payload.Height)
-			case "q", "<C-c>":
+			case "<C-c>":
 				a.handleQuit()
 				return
+			case "q":
+				if !a.promptVisible && !a.formVisible && !a.searchVisible {
+					a.handleQuit()
+					return
+				}
 			}
 
 			// Dispatching events to appropriate handlers.
 			switch {
+			case a.oneShot != nil:
+				a.handleOneShotEvents(e)
+			case a.promptVisible:
+				a.handlePromptEvents(e)
+			case a.formVisible:
+				a.handleFormEvents(e)
+			case a.profilesVisible:
+				a.handleProfilesEvents(ctx, e)
+			case a.keysVisible:
+				a.handleKeysEvents(e)
 			case a.viewerVisible:
-				a.handleViewerEvents(e)
+				a.handleViewerEvents(ctx, e)
 			case a.selectorVisible:
 				a.handleSelectorEvents(ctx, e)
 			case a.messagesVisible:
 				a.handleMessagesEvents(e)
+			case a.chartVisible:
+				a.handleChartEvents(e)
 			default:
-				a.handleScannerEvents(e)
+				a.handleScannerEvents(ctx, e)
 			}
 		}
 	}
 }
 
-func (a *app) handleScannerEvents(e ui.Event) {
+func (a *app) handleScannerEvents(ctx context.Context, e ui.Event) {
 	switch e.ID {
 	case "<Up>":
 		a.scanner.ScrollUp()
@@ -200,29 +321,290 @@ func (a *app) handleScannerEvents(e ui.Event) {
 	case "<End>":
 		a.scanner.ScrollBottom()
 	case "<Enter>":
-		items, rt := a.scanner.Select()
-		switch {
-		case items == nil:
-			a.msgCh <- fmt.Sprintf("Error in selection")
-		case len(items) == 0:
-			a.msgCh <- fmt.Sprintf("No matching keys")
-		default:
-			a.selector.SetItems(items, rt)
-			a.helper.SetText(selectorUsage)
-			a.selectorVisible = true
-		}
+		a.setDecoders()
+		a.showSelector(a.scanner.Select())
+	case "+":
+		a.setDecoders()
+		a.showSelector(a.scanner.SelectAdded())
+	case "-":
+		a.setDecoders()
+		a.showSelector(a.scanner.SelectRemoved())
 	case "e":
 		a.scanner.Enable()
 	case "d":
 		a.scanner.Disable()
+	case "E":
+		a.scanner.EnableAll()
+	case "D":
+		a.scanner.DisableAll()
+	case "s":
+		a.scanner.ScanNow()
+	case "i":
+		if d := a.scanner.Interval(); d > 0 {
+			a.showPrompt("Scan interval", "Interval", d.String(), a.setInterval)
+		}
+	case "o":
+		a.showForm("Scan once", oneShotFields, []string{"", string(r.TypeAuto)}, a.submitOneShot(ctx))
+	case "a":
+		a.showForm("Add scanner", scannerFields, []string{"", "", string(r.TypeKey), defaultInterval.String()}, a.submitScanner("", scanner.Config{}))
+	case "c":
+		if name, cfg := a.scanner.SelectConfig(); cfg != nil {
+			values := []string{name, cfg.Pattern, cfg.Type.String(), cfg.Interval.String()}
+			a.showForm("Change scanner", scannerFields, values, a.submitScanner(name, *cfg))
+		}
+	case "x":
+		if name, cfg := a.scanner.SelectConfig(); cfg != nil {
+			a.showPrompt("Delete scanner", fmt.Sprintf("Delete %q? (y/n)", name), "", func(s string) {
+				if strings.EqualFold(s, "y") || strings.EqualFold(s, "yes") {
+					a.removeScanner(name)
+				}
+			})
+		}
+	case "w":
+		if err := a.cfg.save(); err != nil {
+			a.msgCh <- fmt.Sprintf("Save config: %s", err)
+			return
+		}
+		a.msgCh <- fmt.Sprintf("[saved](fg:green) config to %s (comments are not preserved)", a.cfg.file)
+	case "n":
+		a.scanner.ToggleNodes()
+	case "#":
+		a.toggleSortMode()
 	case "m":
 		a.messages.SetText(strings.Join(a.logger.Messages(), "\n"))
 		a.helper.SetText(messagesUsage)
 		a.messagesVisible = true
+	case "h":
+		a.chart.SetHistory(a.scanner.SelectHistory())
+		a.helper.SetText(chartUsage)
+		a.chartVisible = true
+	case "p":
+		a.profiles.SetItems(a.cfg.profileNames(), a.profile)
+		a.helper.SetText(profilesUsage)
+		a.profilesVisible = true
+	case "?":
+		a.keys.SetText(scannerKeys)
+		a.helper.SetText(keysUsage)
+		a.keysVisible = true
+	}
+}
+
+// setInterval changes the scan interval of the selected worker.
+func (a *app) setInterval(s string) {
+	if d, ok := a.parseInterval(s); ok {
+		a.scanner.SetInterval(d)
+	}
+}
+
+func (a *app) setWatchInterval(s string) {
+	if d, ok := a.parseInterval(s); ok {
+		a.viewer.SetInterval(d)
+	}
+}
+
+// parseInterval parses the interval of the prompt. Invalid intervals are reported in the messages.
+func (a *app) parseInterval(s string) (time.Duration, bool) {
+	d, err := time.ParseDuration(s)
+	switch {
+	case err != nil:
+		a.msgCh <- fmt.Sprintf("Invalid interval: %s", err)
+		return 0, false
+	case d <= 0:
+		a.msgCh <- fmt.Sprintf("Invalid interval: %s", d)
+		return 0, false
+	default:
+		return d, true
+	}
+}
+
+// submitScanner returns a form submit function which validates the scanner fields, and adds or
+// replaces the scanner. Fields not in the form are copied from base.
+func (a *app) submitScanner(orig string, base scanner.Config) func([]string) bool {
+	return func(values []string) bool {
+		name, pattern := strings.TrimSpace(values[0]), values[1]
+		scans := a.cfg.profiles[a.profile].Scans
+		if name == "" || pattern == "" {
+			a.msgCh <- "Scanner name and pattern are required"
+			return false
+		}
+		if _, ok := scans[name]; ok && name != orig {
+			a.msgCh <- fmt.Sprintf("Scanner %q already exists", name)
+			return false
+		}
+
+		var rt r.DataType
+		if err := rt.UnmarshalTOML(strings.TrimSpace(values[2])); err != nil {
+			a.msgCh <- fmt.Sprintf("Invalid type: %s", err)
+			return false
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(values[3]))
+		if err != nil || d <= 0 {
+			a.msgCh <- fmt.Sprintf("Invalid interval: %s", values[3])
+			return false
+		}
+
+		cfg := base
+		cfg.Pattern = pattern
+		cfg.Type = rt
+		cfg.Interval = common.Duration{Duration: d}
+
+		if orig != "" && orig != name {
+			a.removeScanner(orig)
+		}
+		scans[name] = &cfg
+		a.scanner.AddWorker(name, &cfg)
+		return true
+	}
+}
+
+// submitOneShot returns a form submit function which validates the pattern and the type, and starts
+// a one-shot scan.
+func (a *app) submitOneShot(ctx context.Context) func([]string) bool {
+	return func(values []string) bool {
+		pattern := values[0]
+		if pattern == "" {
+			a.msgCh <- "Pattern is required"
+			return false
+		}
+
+		rt := r.TypeAuto
+		if t := strings.TrimSpace(values[1]); t != "" {
+			if err := rt.UnmarshalTOML(t); err != nil {
+				a.msgCh <- fmt.Sprintf("Invalid type: %s", err)
+				return false
+			}
+		}
+
+		a.oneShot = scanner.NewOneShot(ctx, a.rc, pattern, rt)
+		a.logger.Attach(a.oneShot.Messages())
+		return true
+	}
+}
+
+func (a *app) handleOneShotEvents(e ui.Event) {
+	switch e.ID {
+	case "<Escape>":
+		a.oneShot.Cancel()
+		a.oneShot = nil
+		a.helper.SetText(a.usage())
+		a.msgCh <- "One-shot scan cancelled"
+	}
+}
+
+// updateOneShot shows the progress of the one-shot scan, and the matching keys in the selector once
+// the scan is done.
+func (a *app) updateOneShot() {
+	if a.oneShot.Done() {
+		items, rt, types := a.oneShot.Result()
+		a.oneShot = nil
+		// One-shot scans have no decoders configured.
+		_ = a.viewer.SetDecoders(nil)
+		a.showSelector(items, rt, types)
+		return
+	}
+
+	pattern, rt := a.oneShot.Pattern()
+	p := a.oneShot.Progress()
+	text := fmt.Sprintf("Pattern: [%s](fg:yellow) (%s)\nFound:   %d keys", pattern, rt, p.Keys)
+	if p.Nodes > 1 {
+		text += fmt.Sprintf(", node %d/%d", p.Node, p.Nodes)
+	}
+	a.progress.SetText(text)
+	a.progress.Update()
+}
+
+// removeScanner stops the named scanner and removes it from the config.
+func (a *app) removeScanner(name string) {
+	a.scanner.RemoveWorker(name)
+	delete(a.cfg.profiles[a.profile].Scans, name)
+}
+
+// showForm shows the form on top of the current widget. submit is called with the values when the
+// form is submitted.
+func (a *app) showForm(title string, labels, values []string, submit func([]string) bool) {
+	a.form.SetForm(title, labels, values)
+	a.formSubmit = submit
+	a.helper.SetText(formUsage)
+	a.formVisible = true
+}
+
+func (a *app) handleFormEvents(e ui.Event) {
+	switch e.ID {
+	case "<Enter>":
+		if a.formSubmit(a.form.Values()) {
+			a.formVisible = false
+			a.helper.SetText(a.usage())
+		}
+	case "<Escape>":
+		a.formVisible = false
+		a.helper.SetText(a.usage())
+	default:
+		a.form.HandleKey(e)
+	}
+}
+
+// showPrompt shows the prompt on top of the current widget. submit is called with the input when
+// the prompt is submitted.
+func (a *app) showPrompt(title, label, value string, submit func(string)) {
+	a.prompt.SetPrompt(title, label, value)
+	a.promptSubmit = submit
+	a.helper.SetText(promptUsage)
+	a.promptVisible = true
+}
+
+func (a *app) handlePromptEvents(e ui.Event) {
+	switch e.ID {
+	case "<Enter>":
+		a.promptVisible = false
+		a.helper.SetText(a.usage())
+		a.promptSubmit(a.prompt.Value())
+	case "<Escape>":
+		a.promptVisible = false
+		a.helper.SetText(a.usage())
+	default:
+		a.prompt.HandleKey(e)
+	}
+}
+
+// setDecoders applies the decoders of the selected scanner to the values in the viewer.
+func (a *app) setDecoders() {
+	var names []string
+	if _, cfg := a.scanner.SelectConfig(); cfg != nil {
+		names = cfg.Decoders
+	}
+	if err := a.viewer.SetDecoders(names); err != nil {
+		a.msgCh <- fmt.Sprintf("Invalid decoders: %s", err)
+	}
+}
+
+// showSelector shows the keys in the selector widget.
+func (a *app) showSelector(items []string, rt r.DataType, types map[string]r.DataType) {
+	switch {
+	case items == nil:
+		a.msgCh <- fmt.Sprintf("Error in selection")
+		// Replaces the usage of a finished one-shot scan.
+		a.helper.SetText(a.usage())
+	case len(items) == 0:
+		a.msgCh <- fmt.Sprintf("No matching keys")
+		a.helper.SetText(a.usage())
+	default:
+		a.selector.SetItems(items, rt, types)
+		a.tree.SetItems(items, rt, types)
+		a.helper.SetText(a.selectorUsage())
+		a.selectorVisible = true
 	}
 }
 
 func (a *app) handleSelectorEvents(ctx context.Context, e ui.Event) {
+	switch {
+	case a.treeMode:
+		a.handleTreeEvents(ctx, e)
+		return
+	case a.searchVisible:
+		a.handleSearchEvents(e)
+		return
+	}
+
 	switch e.ID {
 	case "<Up>":
 		a.selector.ScrollUp()
@@ -237,25 +619,144 @@ func (a *app) handleSelectorEvents(ctx context.Context, e ui.Event) {
 	case "<End>":
 		a.selector.ScrollBottom()
 	case "<Enter>":
-		c, cancel := context.WithTimeout(ctx, viewerTimeout)
-		defer cancel()
-		key, rt := a.selector.Select()
-		a.viewer.View(c, key, rt)
-		a.helper.SetText(viewerUsage)
+		if key, rt := a.selector.Select(); key != "" {
+			a.showViewer(ctx, key, rt)
+		}
+	case "/":
+		a.search.SetPrompt("Search", a.searchMode.String(), "")
+		a.selector.ClearFilter()
+		a.helper.SetText(searchUsage)
+		a.searchVisible = true
+	case "n":
+		a.selector.NextMatch()
+	case "N":
+		a.selector.PrevMatch()
+	case "c":
+		a.selector.ToggleColumns()
+	case "s":
+		a.selector.NextSort()
+	case "S":
+		a.selector.ReverseSort()
+	case "#":
+		a.toggleSortMode()
+	case "?":
+		a.keys.SetText(selectorKeys)
+		a.helper.SetText(keysUsage)
+		a.keysVisible = true
+	case "t":
+		a.treeMode = true
+		a.helper.SetText(treeUsage)
+	case "<Escape>":
+		switch {
+		case a.selector.Filtered():
+			a.selector.ShowAll()
+			return
+		case a.selector.Highlighted():
+			a.selector.ClearFilter()
+			return
+		}
 		a.selectorVisible = false
-		a.viewerVisible = true
+		a.helper.SetText(scannerUsage)
+	}
+}
+
+func (a *app) handleSearchEvents(e ui.Event) {
+	switch e.ID {
+	case "<Enter>":
+		a.searchVisible = false
+		a.helper.SetText(selectorUsage)
+		return
+	case "<Escape>":
+		a.searchVisible = false
+		a.selector.ClearFilter()
+		a.helper.SetText(selectorUsage)
+		return
+	case "<Tab>":
+		a.searchMode = a.searchMode.Next()
+	default:
+		a.search.HandleKey(e)
+	}
+
+	query := a.search.Value()
+	title := "Search"
+	if err := a.selector.Filter(query, a.searchMode); err != nil {
+		title = "Search - invalid pattern"
+	}
+	a.search.SetPrompt(title, a.searchMode.String(), query)
+}
+
+func (a *app) handleTreeEvents(ctx context.Context, e ui.Event) {
+	switch e.ID {
+	case "<Up>":
+		a.tree.ScrollUp()
+	case "<Down>":
+		a.tree.ScrollDown()
+	case "<PageUp>":
+		a.tree.ScrollPageUp()
+	case "<PageDown>":
+		a.tree.ScrollPageDown()
+	case "<Home>":
+		a.tree.ScrollTop()
+	case "<End>":
+		a.tree.ScrollBottom()
+	case "<Right>":
+		a.tree.Expand()
+	case "<Left>":
+		a.tree.Collapse()
+	case "<Enter>":
+		if key, rt, ok := a.tree.Select(); ok {
+			a.showViewer(ctx, key, rt)
+			return
+		}
+		a.tree.Toggle()
+	case "#":
+		a.toggleSortMode()
+	case "t":
+		a.treeMode = false
+		a.helper.SetText(selectorUsage)
 	case "<Escape>":
 		a.selectorVisible = false
 		a.helper.SetText(scannerUsage)
 	}
 }
 
-func (a *app) handleViewerEvents(e ui.Event) {
+// toggleSortMode switches between natural and lexicographic order of the scanners and the keys.
+func (a *app) toggleSortMode() {
+	a.sortMode = a.sortMode.Next()
+	a.scanner.SetSortMode(a.sortMode)
+	a.selector.SetSortMode(a.sortMode)
+	a.tree.SetSortMode(a.sortMode)
+	a.msgCh <- fmt.Sprintf("sorting names in [%s](fg:green) order", a.sortMode)
+}
+
+// showViewer shows the details of the key in the viewer widget.
+func (a *app) showViewer(ctx context.Context, key string, rt r.DataType) {
+	c, cancel := context.WithTimeout(ctx, viewerTimeout)
+	defer cancel()
+	a.viewer.View(c, key, rt)
+	a.helper.SetText(viewerUsage)
+	a.selectorVisible = false
+	a.viewerVisible = true
+}
+
+// selectorUsage returns the usage of the selector or the tree, whichever is shown.
+func (a *app) selectorUsage() string {
+	switch {
+	case a.treeMode:
+		return treeUsage
+	case a.searchVisible:
+		return searchUsage
+	default:
+		return selectorUsage
+	}
+}
+
+func (a *app) handleViewerEvents(ctx context.Context, e ui.Event) {
 	switch e.ID {
 	case "<Escape>":
 		a.viewerVisible = false
 		a.selectorVisible = true
-		a.helper.SetText(selectorUsage)
+		a.helper.SetText(a.selectorUsage())
 	case "<Up>":
 		a.viewer.ScrollUp()
 	case "<Down>":
@@ -268,6 +769,30 @@ func (a *app) handleViewerEvents(e ui.Event) {
 		a.viewer.ScrollTop()
 	case "<End>":
 		a.viewer.ScrollBottom()
+	case "<Enter>", "<Space>":
+		a.viewer.ToggleFold()
+	case "p":
+		a.viewer.TogglePretty()
+	case "d":
+		a.viewer.NextDecoder()
+	case "x":
+		a.viewer.ToggleHex()
+	case "r":
+		c, cancel := context.WithTimeout(ctx, viewerTimeout)
+		a.viewer.Refresh(c)
+		cancel()
+	case "o":
+		c, cancel := context.WithTimeout(ctx, viewerTimeout)
+		a.viewer.ToggleOrder(c)
+		cancel()
+	case "w":
+		a.viewer.ToggleWatch()
+	case "i":
+		a.showPrompt("Watch interval", "Interval", a.viewer.Interval().String(), a.setWatchInterval)
+	case "?":
+		a.keys.SetText(viewerKeys)
+		a.helper.SetText(keysUsage)
+		a.keysVisible = true
 	}
 }
 
@@ -279,28 +804,159 @@ func (a *app) handleMessagesEvents(e ui.Event) {
 	}
 }
 
+func (a *app) handleKeysEvents(e ui.Event) {
+	switch e.ID {
+	case "<Escape>":
+		a.keysVisible = false
+		a.helper.SetText(a.usage())
+	}
+}
+
+func (a *app) handleChartEvents(e ui.Event) {
+	switch e.ID {
+	case "<Escape>":
+		a.chartVisible = false
+		a.helper.SetText(scannerUsage)
+	}
+}
+
+func (a *app) handleProfilesEvents(ctx context.Context, e ui.Event) {
+	switch e.ID {
+	case "<Up>":
+		a.profiles.ScrollUp()
+	case "<Down>":
+		a.profiles.ScrollDown()
+	case "<PageUp>":
+		a.profiles.ScrollPageUp()
+	case "<PageDown>":
+		a.profiles.ScrollPageDown()
+	case "<Home>":
+		a.profiles.ScrollTop()
+	case "<End>":
+		a.profiles.ScrollBottom()
+	case "<Enter>":
+		a.switchProfile(ctx, a.profiles.Select())
+		a.profilesVisible = false
+		a.helper.SetText(scannerUsage)
+	case "<Escape>":
+		a.profilesVisible = false
+		a.helper.SetText(scannerUsage)
+	}
+}
+
+// switchProfile connects to the Redis server of the named profile and rebuilds the scanner and viewer
+// widgets against the new client. The current connection is kept if the new one cannot be established.
+func (a *app) switchProfile(ctx context.Context, name string) {
+	p, ok := a.cfg.profiles[name]
+	if !ok || name == a.profile {
+		return
+	}
+
+	rc, err := connect(&p.Config)
+	if err != nil {
+		a.msgCh <- fmt.Sprintf("switch to profile %q: %s", name, err)
+		return
+	}
+
+	a.scanner.Close()
+	a.selector.Close()
+	a.viewer.Close()
+	a.rc.Close()
+
+	a.rc = rc
+	a.profile = name
+	a.scanner = scanner.NewScanner(ctx, a.rc, p.Scans, a.profileLabel(), a.sortMode)
+	a.selector = scanner.NewSelector(ctx, a.rc, a.sortMode)
+	a.viewer = scanner.NewViewer(ctx, a.rc, a.cfg.decoders, a.cfg.WatchInterval.Duration)
+	a.logger.Attach(a.scanner.Messages())
+	a.logger.Attach(a.viewer.Messages())
+	a.resize(ui.TerminalDimensions())
+
+	a.msgCh <- fmt.Sprintf("switched to profile [%s](fg:green)", name)
+}
+
+// profileLabel returns the name of the active profile if there are multiple profiles configured.
+func (a *app) profileLabel() string {
+	if len(a.cfg.profiles) < 2 {
+		return ""
+	}
+	return a.profile
+}
+
 // update invokes the Update() method on each widget.
 func (a *app) update() {
 	a.helper.Update()
 	a.logger.Update()
 	switch {
+	case a.profilesVisible:
+		a.profiles.Update()
+	case a.keysVisible:
+		a.keys.Update()
 	case a.viewerVisible:
 		a.viewer.Update()
+	case a.selectorVisible && a.treeMode:
+		a.tree.Update()
 	case a.selectorVisible:
 		a.selector.Update()
+		if a.searchVisible {
+			a.search.Update()
+		}
 	case a.messagesVisible:
 		a.messages.Update()
+	case a.chartVisible:
+		// Keep the chart in sync with the scans of the selected worker.
+		a.chart.SetHistory(a.scanner.SelectHistory())
+		a.chart.Update()
 	default:
 		a.scanner.Update()
 	}
+	if a.promptVisible {
+		a.prompt.Update()
+	}
+	if a.formVisible {
+		a.form.Update()
+	}
+	if a.oneShot != nil {
+		a.updateOneShot()
+	}
+}
+
+// usage returns the usage of the visible widget.
+func (a *app) usage() string {
+	switch {
+	case a.oneShot != nil:
+		return oneShotUsage
+	case a.profilesVisible:
+		return profilesUsage
+	case a.keysVisible:
+		return keysUsage
+	case a.viewerVisible:
+		return viewerUsage
+	case a.selectorVisible:
+		return a.selectorUsage()
+	case a.messagesVisible:
+		return messagesUsage
+	case a.chartVisible:
+		return chartUsage
+	default:
+		return scannerUsage
+	}
 }
 
 // resize resizes all widgets.
 func (a *app) resize(w, h int) {
 	a.scanner.Resize(0, 0, w, h-5)
 	a.selector.Resize(0, 0, w, h-5)
+	a.tree.Resize(0, 0, w, h-5)
+	a.search.Resize(0, h-8, w, h-5)
 	a.viewer.Resize(0, 0, w, h-5)
 	a.messages.Resize(0, 0, w, h-5)
+	a.keys.Resize(0, 0, w, h-5)
+	a.prompt.Resize(w/4, (h-5)/2-1, w-w/4, (h-5)/2+2)
+	a.form.Resize(w/4, (h-5)/2-3, w-w/4, (h-5)/2+3)
+	a.progress.Resize(w/4, (h-5)/2-2, w-w/4, (h-5)/2+2)
+	a.chart.Resize(0, 0, w, h-5)
+	a.profiles.Resize(0, 0, w, h-5)
 	a.helper.Resize(0, h-5, w/2, h)
 	a.logger.Resize(w/2, h-5, w, h)
 
@@ -309,11 +965,22 @@ func (a *app) resize(w, h int) {
 
 // handleQuit invokes the Close() method on each widget and closes termui.
 func (a *app) handleQuit() {
+	if a.oneShot != nil {
+		a.oneShot.Cancel()
+	}
 	close(a.msgCh)
 
+	a.profiles.Close()
 	a.messages.Close()
+	a.keys.Close()
+	a.prompt.Close()
+	a.search.Close()
+	a.form.Close()
+	a.progress.Close()
+	a.chart.Close()
 	a.viewer.Close()
 	a.selector.Close()
+	a.tree.Close()
 	a.scanner.Close()
 	a.helper.Close()
 	a.logger.Close()
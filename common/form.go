@@ -0,0 +1,97 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+)
+
+type form struct {
+	*widgets.Paragraph
+
+	labels     []string
+	values     [][]rune
+	active     int
+	labelWidth int
+}
+
+func NewForm() *form {
+	f := &form{
+		Paragraph: widgets.NewParagraph(),
+	}
+	f.BorderStyle = ui.NewStyle(ui.ColorYellow)
+
+	return f
+}
+
+func (f *form) Update() {
+	rows := make([]string, len(f.labels))
+	for i, label := range f.labels {
+		cursor := ""
+		if i == f.active {
+			cursor = "[ ](bg:white)"
+		}
+		// Only the displayed values are escaped.
+		rows[i] = fmt.Sprintf("[%*s:](fg:cyan) %s%s", f.labelWidth, label, EscapeMarkup(string(f.values[i])), cursor)
+	}
+	f.Text = strings.Join(rows, "\n")
+	ui.Render(f)
+}
+
+func (f *form) Resize(x1, y1, x2, y2 int) {
+	f.SetRect(x1, y1, x2, y2)
+}
+
+func (f *form) Close() {}
+
+func (f *form) SetForm(title string, labels, values []string) {
+	f.Title = fmt.Sprintf(" %s ", title)
+	f.labels = labels
+	f.values = make([][]rune, len(labels))
+	f.active = 0
+	f.labelWidth = 0
+	for i, label := range labels {
+		if i < len(values) {
+			f.values[i] = []rune(values[i])
+		}
+		if len(label) > f.labelWidth {
+			f.labelWidth = len(label)
+		}
+	}
+}
+
+func (f *form) HandleKey(e ui.Event) {
+	if len(f.labels) == 0 {
+		return
+	}
+	v := f.values[f.active]
+	switch e.ID {
+	case "<Tab>", "<Down>":
+		f.active = (f.active + 1) % len(f.labels)
+	case "<Up>":
+		f.active = (f.active + len(f.labels) - 1) % len(f.labels)
+	case "<Backspace>", "<C-<Backspace>>":
+		if len(v) > 0 {
+			f.values[f.active] = v[:len(v)-1]
+		}
+	case "<C-u>":
+		f.values[f.active] = v[:0]
+	case "<Space>":
+		f.values[f.active] = append(v, ' ')
+	default:
+		if e.Type == ui.KeyboardEvent && utf8.RuneCountInString(e.ID) == 1 {
+			f.values[f.active] = append(v, []rune(e.ID)...)
+		}
+	}
+}
+
+func (f *form) Values() []string {
+	values := make([]string, len(f.values))
+	for i, v := range f.values {
+		values[i] = string(v)
+	}
+	return values
+}
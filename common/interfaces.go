@@ -1,5 +1,9 @@
 package common
 
+import (
+	ui "github.com/gizak/termui/v3"
+)
+
 // Widget provides an interface to interact with widgets.
 // A widget renders some sort of data to a termui widget.
 type Widget interface {
@@ -38,3 +42,43 @@ type TextBox interface {
 	// SetText renders the provided string to the screen.
 	SetText(string)
 }
+
+// Picker is implemented by widgets which let the user pick an item from a list.
+type Picker interface {
+	Widget
+	Scrollable
+
+	// SetItems sets the list of items and marks the active one.
+	SetItems([]string, string)
+
+	// Select returns the selected item.
+	Select() string
+}
+
+// Prompt is implemented by widgets which read a single line of text input.
+type Prompt interface {
+	Widget
+
+	// SetPrompt sets the title, the label and the initial value of the input.
+	SetPrompt(string, string, string)
+
+	// HandleKey edits the input based on the keyboard event.
+	HandleKey(ui.Event)
+
+	// Value returns the input.
+	Value() string
+}
+
+// Form is implemented by widgets which read multiple fields of text input.
+type Form interface {
+	Widget
+
+	// SetForm sets the title, the field labels and the initial field values of the form.
+	SetForm(string, []string, []string)
+
+	// HandleKey moves between the fields or edits the active field based on the keyboard event.
+	HandleKey(ui.Event)
+
+	// Values returns the field values.
+	Values() []string
+}
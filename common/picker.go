@@ -0,0 +1,65 @@
+package common
+
+import (
+	"fmt"
+	"sync"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+)
+
+type picker struct {
+	*widgets.List
+
+	items  []string
+	active string
+	mtx    sync.Mutex
+}
+
+func NewPicker(title string) *picker {
+	p := &picker{
+		List: widgets.NewList(),
+	}
+	p.Title = title
+	p.SelectedRowStyle = ui.NewStyle(ui.ColorWhite, ui.ColorBlue)
+
+	return p
+}
+
+func (p *picker) Update() {
+	ui.Render(p)
+}
+
+func (p *picker) Resize(x1, y1, x2, y2 int) {
+	p.SetRect(x1, y1, x2, y2)
+}
+
+func (p *picker) Close() {}
+
+func (p *picker) SetItems(items []string, active string) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.items = items
+	p.active = active
+	p.SelectedRow = 0
+	p.Rows = make([]string, len(items))
+	for i, item := range items {
+		if item == active {
+			p.SelectedRow = i
+			p.Rows[i] = fmt.Sprintf("[* %s](fg:green)", item)
+			continue
+		}
+		p.Rows[i] = "  " + item
+	}
+}
+
+func (p *picker) Select() string {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if len(p.items) == 0 {
+		return ""
+	}
+	return p.items[p.SelectedRow]
+}
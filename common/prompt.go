@@ -0,0 +1,64 @@
+package common
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+)
+
+type prompt struct {
+	*widgets.Paragraph
+
+	label string
+	value []rune
+}
+
+func NewPrompt() *prompt {
+	p := &prompt{
+		Paragraph: widgets.NewParagraph(),
+	}
+	p.BorderStyle = ui.NewStyle(ui.ColorYellow)
+
+	return p
+}
+
+func (p *prompt) Update() {
+	// Only the displayed value is escaped.
+	p.Text = fmt.Sprintf("[%s:](fg:cyan) %s[ ](bg:white)", p.label, EscapeMarkup(string(p.value)))
+	ui.Render(p)
+}
+
+func (p *prompt) Resize(x1, y1, x2, y2 int) {
+	p.SetRect(x1, y1, x2, y2)
+}
+
+func (p *prompt) Close() {}
+
+func (p *prompt) SetPrompt(title, label, value string) {
+	p.Title = fmt.Sprintf(" %s ", title)
+	p.label = label
+	p.value = []rune(value)
+}
+
+func (p *prompt) HandleKey(e ui.Event) {
+	switch e.ID {
+	case "<Backspace>", "<C-<Backspace>>":
+		if len(p.value) > 0 {
+			p.value = p.value[:len(p.value)-1]
+		}
+	case "<C-u>":
+		p.value = p.value[:0]
+	case "<Space>":
+		p.value = append(p.value, ' ')
+	default:
+		if e.Type == ui.KeyboardEvent && utf8.RuneCountInString(e.ID) == 1 {
+			p.value = append(p.value, []rune(e.ID)...)
+		}
+	}
+}
+
+func (p *prompt) Value() string {
+	return string(p.value)
+}
@@ -0,0 +1,102 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SortMode is the order of sorted keys and names.
+type SortMode string
+
+const (
+	// SortNatural compares runs of digits as numbers, so that "job:2" is sorted before "job:10".
+	SortNatural SortMode = "natural"
+	// SortLexicographic compares strings byte-wise.
+	SortLexicographic SortMode = "lexicographic"
+)
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (m *SortMode) UnmarshalText(text []byte) error {
+	switch mode := SortMode(text); mode {
+	case SortNatural, SortLexicographic:
+		*m = mode
+		return nil
+	default:
+		return fmt.Errorf("invalid sort mode: %s", text)
+	}
+}
+
+// Next returns the other sort mode.
+func (m SortMode) Next() SortMode {
+	if m == SortLexicographic {
+		return SortNatural
+	}
+	return SortLexicographic
+}
+
+// Less reports whether a is sorted before b. The zero value sorts naturally.
+func (m SortMode) Less(a, b string) bool {
+	if m == SortLexicographic {
+		return a < b
+	}
+	return NaturalLess(a, b)
+}
+
+// Sort sorts the strings in place.
+func (m SortMode) Sort(s []string) {
+	if m == SortLexicographic {
+		sort.Strings(s)
+		return
+	}
+	sort.Slice(s, func(i, j int) bool {
+		return NaturalLess(s[i], s[j])
+	})
+}
+
+// NaturalLess reports whether a is sorted before b in natural order. Runs of digits are compared by
+// their numeric value, everything else byte-wise. Equal numbers with fewer leading zeros are sorted first.
+func NaturalLess(a, b string) bool {
+	i, j, zeros := 0, 0, 0
+	for i < len(a) && j < len(b) {
+		if !isDigit(a[i]) || !isDigit(b[j]) {
+			if a[i] != b[j] {
+				return a[i] < b[j]
+			}
+			i, j = i+1, j+1
+			continue
+		}
+
+		// Skip leading zeros, then compare the runs of digits by length and value.
+		si, sj := i, j
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+		if zeros == 0 {
+			zeros = (i - si) - (j - sj)
+		}
+		ni, nj := i, j
+		for i < len(a) && isDigit(a[i]) {
+			i++
+		}
+		for j < len(b) && isDigit(b[j]) {
+			j++
+		}
+		if na, nb := a[ni:i], b[nj:j]; len(na) != len(nb) {
+			return len(na) < len(nb)
+		} else if na != nb {
+			return na < nb
+		}
+	}
+	if len(a)-i != len(b)-j {
+		return len(a)-i < len(b)-j
+	}
+	return zeros < 0
+}
+
+// isDigit returns true if c is an ASCII digit.
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
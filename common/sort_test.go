@@ -0,0 +1,140 @@
+package common
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "job:2", b: "job:10", want: true},
+		{a: "job:10", b: "job:2", want: false},
+		{a: "job:9:x", b: "job:10:a", want: true},
+		{a: "1", b: "01", want: true},
+		{a: "01", b: "1", want: false},
+		{a: "01", b: "001", want: true},
+		{a: "001", b: "01", want: false},
+		{a: "01", b: "2", want: true},
+		{a: "0", b: "00", want: true},
+		{a: "a01b2", b: "a1b3", want: true},
+		{a: "a01b3", b: "a1b2", want: false},
+		{a: "a01b", b: "a1c", want: true},
+		{a: "key1", b: "key2", want: true},
+		{a: "key99", b: "key100", want: true},
+		{a: "key100", b: "key99", want: false},
+		{a: "18446744073709551616", b: "18446744073709551617", want: true},
+		{a: "99999999999999999999", b: "100000000000000000000", want: true},
+		{a: "user", b: "user:1", want: true},
+		{a: "user:1", b: "user", want: false},
+		{a: "user:1", b: "user:10", want: true},
+		{a: "", b: "a", want: true},
+		{a: "a", b: "", want: false},
+		{a: "", b: "", want: false},
+		{a: "job:10", b: "job:10", want: false},
+		{a: "job:007", b: "job:007", want: false},
+		{a: "a", b: "b", want: true},
+		{a: "B", b: "a", want: true},
+		{a: "9", b: "a", want: true},
+		{a: "a", b: "9", want: false},
+		{a: "x1", b: "x-1", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" < "+tt.b, func(t *testing.T) {
+			if got := NaturalLess(tt.a, tt.b); got != tt.want {
+				t.Errorf("NaturalLess(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+// naturalStrings returns every string of up to n characters of a small alphabet of digits, zeros and
+// letters, which covers the runs of digits and leading zeros at any position.
+func naturalStrings(n int) []string {
+	alphabet := []string{"0", "1", "9", "a", ":"}
+	all := []string{""}
+	prev := []string{""}
+	for i := 0; i < n; i++ {
+		var next []string
+		for _, s := range prev {
+			for _, c := range alphabet {
+				next = append(next, s+c)
+			}
+		}
+		all = append(all, next...)
+		prev = next
+	}
+	return all
+}
+
+func TestNaturalLessStrictWeakOrdering(t *testing.T) {
+	all := naturalStrings(3)
+
+	for _, a := range all {
+		if NaturalLess(a, a) {
+			t.Fatalf("NaturalLess(%q, %q) = true, want irreflexive", a, a)
+		}
+		for _, b := range all {
+			ab, ba := NaturalLess(a, b), NaturalLess(b, a)
+			if ab && ba {
+				t.Fatalf("NaturalLess(%q, %q) and NaturalLess(%q, %q) are both true", a, b, b, a)
+			}
+			// Distinct strings are never equivalent, so that the order is total.
+			if a != b && !ab && !ba {
+				t.Fatalf("%q and %q are equivalent", a, b)
+			}
+			if !ab {
+				continue
+			}
+			for _, c := range all {
+				if NaturalLess(b, c) && !NaturalLess(a, c) {
+					t.Fatalf("NaturalLess(%q, %q) and NaturalLess(%q, %q), but not NaturalLess(%q, %q)", a, b, b, c, a, c)
+				}
+			}
+		}
+	}
+}
+
+func TestSortModeSort(t *testing.T) {
+	items := []string{"job:10", "job:2", "job:01", "job:1", "job", "job:001", "job:a"}
+	rand.New(rand.NewSource(1)).Shuffle(len(items), func(i, j int) {
+		items[i], items[j] = items[j], items[i]
+	})
+
+	tests := []struct {
+		mode SortMode
+		want []string
+	}{
+		{mode: SortNatural, want: []string{"job", "job:1", "job:01", "job:001", "job:2", "job:10", "job:a"}},
+		{mode: SortLexicographic, want: []string{"job", "job:001", "job:01", "job:1", "job:10", "job:2", "job:a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			s := append([]string(nil), items...)
+			tt.mode.Sort(s)
+			for i := range s {
+				if s[i] != tt.want[i] {
+					t.Fatalf("Sort() = %q, want %q", s, tt.want)
+				}
+			}
+			if !sort.SliceIsSorted(s, func(i, j int) bool { return tt.mode.Less(s[i], s[j]) }) {
+				t.Errorf("Sort() = %q is not sorted by Less", s)
+			}
+		})
+	}
+}
+
+func TestSortModeUnmarshalText(t *testing.T) {
+	var m SortMode
+	if err := m.UnmarshalText([]byte("lexicographic")); err != nil || m != SortLexicographic {
+		t.Errorf("UnmarshalText(lexicographic) = %s, %v", m, err)
+	}
+	if err := m.UnmarshalText([]byte("random")); err == nil {
+		t.Error("UnmarshalText(random) error = nil, want error")
+	}
+}
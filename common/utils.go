@@ -1,10 +1,28 @@
 package common
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
 )
 
+var (
+	// markupReplacer replaces the square brackets of style markup with left and right square brackets
+	// with quill.
+	markupReplacer = strings.NewReplacer("[", "⁅", "]", "⁆")
+)
+
+// EscapeMarkup replaces the square brackets of the text with look-alike characters of the same width.
+// termui has no way to escape them, and unbalanced brackets break the style markup of the row.
+func EscapeMarkup(text string) string {
+	return markupReplacer.Replace(text)
+}
+
 // LoadFile returns a byte slice containing the contents of the given file.
 //
 // Will return an error if the file contents have a length of 0.
@@ -20,3 +38,29 @@ func LoadFile(file string) ([]byte, error) {
 
 	return b, nil
 }
+
+// RunCommand runs the given command line in the system shell and returns its standard output.
+// The environment variables in env ("KEY=value") are added to the environment of the command.
+func RunCommand(ctx context.Context, command string, env ...string) ([]byte, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+
+	return out, nil
+}
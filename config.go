@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/milonoir/rv/common"
+	r "github.com/milonoir/rv/redis"
+	"github.com/milonoir/rv/scanner"
+)
+
+const (
+	defaultProfile   = "default"
+	defaultDelimiter = ":"
+	// defaultWatchInterval is the interval of refreshing the watched key in the viewer.
+	defaultWatchInterval = 2 * time.Second
+)
+
+var (
+	// reservedProfiles are the names of the redis sub-sections which belong to a single connection
+	// and therefore cannot be used as profile names.
+	reservedProfiles = map[string]bool{
+		"sentinel": true,
+		"tls":      true,
+	}
+)
+
+// config represents the application configuration.
+type config struct {
+	Profile   string
+	Delimiter string
+	Sort      common.SortMode
+	Redis     toml.Primitive
+	Scans     map[string]*scanner.Config
+
+	// ProtoDescriptors is the protobuf descriptor set file of the messages decoded by the viewer.
+	ProtoDescriptors string `toml:"proto_descriptors"`
+	// WatchInterval is the interval of refreshing the watched key in the viewer.
+	WatchInterval common.Duration `toml:"watch_interval"`
+
+	file     string
+	decoders *scanner.Decoders
+	profiles map[string]*profile
+	// profileSet is true if the redis section consists of profile sub-sections.
+	profileSet bool
+}
+
+// profile is a named Redis connection with its own set of scanners.
+type profile struct {
+	r.Config
+
+	Scans map[string]*scanner.Config `toml:"scans"`
+
+	// ownScans is false if the profile uses the top-level scanners. An empty scans table of the
+	// profile means that it has no scanners.
+	ownScans bool
+}
+
+// loadConfig loads and parses the configuration file.
+//
+// The redis section either configures a single connection, or each of its sub-sections
+// configures a named connection profile. Profiles without a scans table of their own use the
+// scanners of the top-level scans section.
+func loadConfig(file string) (*config, error) {
+	f, err := common.LoadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("load config file: %w", err)
+	}
+
+	cfg := &config{file: file}
+	md, err := toml.Decode(string(f), cfg)
+	if err != nil {
+		return nil, fmt.Errorf("parse toml config: %w", err)
+	}
+	if cfg.Scans == nil {
+		cfg.Scans = make(map[string]*scanner.Config)
+	}
+	if cfg.Delimiter == "" {
+		cfg.Delimiter = defaultDelimiter
+	}
+	if cfg.Sort == "" {
+		cfg.Sort = common.SortNatural
+	}
+	if cfg.WatchInterval.Duration <= 0 {
+		cfg.WatchInterval.Duration = defaultWatchInterval
+	}
+
+	if err = cfg.parseProfiles(md); err != nil {
+		return nil, fmt.Errorf("parse redis config: %w", err)
+	}
+	if err = cfg.checkProfiles(); err != nil {
+		return nil, err
+	}
+
+	if cfg.Profile == "" {
+		cfg.Profile = cfg.profileNames()[0]
+	}
+	if _, ok := cfg.profiles[cfg.Profile]; !ok {
+		return nil, fmt.Errorf("unknown profile: %s", cfg.Profile)
+	}
+
+	if cfg.decoders, err = scanner.NewDecoders(cfg.ProtoDescriptors); err != nil {
+		return nil, fmt.Errorf("load decoders: %w", err)
+	}
+	if err = cfg.checkDecoders(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// checkProfiles checks that the connection of every profile is valid.
+func (c *config) checkProfiles() error {
+	for name, p := range c.profiles {
+		if err := p.Config.Validate(); err != nil {
+			return fmt.Errorf("profile %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// checkDecoders checks that the decoders of every scanner exist.
+func (c *config) checkDecoders() error {
+	for name, p := range c.profiles {
+		for scan, s := range p.Scans {
+			if _, err := c.decoders.Pipeline(s.Decoders); err != nil {
+				return fmt.Errorf("profile %s: scanner %s: %w", name, scan, err)
+			}
+		}
+	}
+	return nil
+}
+
+// parseProfiles decodes the redis section into connection profiles.
+func (c *config) parseProfiles(md toml.MetaData) error {
+	var raw map[string]interface{}
+	if err := md.PrimitiveDecode(c.Redis, &raw); err != nil {
+		return err
+	}
+
+	if !isProfileSet(raw) {
+		p := &profile{}
+		if err := md.PrimitiveDecode(c.Redis, &p.Config); err != nil {
+			return err
+		}
+		c.profiles = map[string]*profile{defaultProfile: p}
+		c.setDefaultScans(md)
+		return nil
+	}
+
+	var sections map[string]toml.Primitive
+	if err := md.PrimitiveDecode(c.Redis, &sections); err != nil {
+		return err
+	}
+	c.profileSet = true
+	c.profiles = make(map[string]*profile, len(sections))
+	for name, section := range sections {
+		p := &profile{}
+		if err := md.PrimitiveDecode(section, p); err != nil {
+			return fmt.Errorf("profile %s: %w", name, err)
+		}
+		c.profiles[name] = p
+	}
+	c.setDefaultScans(md)
+
+	return nil
+}
+
+// setDefaultScans sets the top-level scanners to the profiles without a scans table. Profiles with
+// an empty scans table have no scanners.
+func (c *config) setDefaultScans(md toml.MetaData) {
+	for name, p := range c.profiles {
+		if !c.profileSet || !md.IsDefined("redis", name, "scans") {
+			p.Scans = c.Scans
+			continue
+		}
+		if p.Scans == nil {
+			p.Scans = make(map[string]*scanner.Config)
+		}
+		p.ownScans = true
+	}
+}
+
+// save writes the scanners of the profiles back to the configuration file. The scanners of a profile
+// whose scanners have all been deleted are written as an empty table, so that it does not fall back
+// to the top-level scanners. Other sections are preserved, but comments and formatting are not.
+func (c *config) save() error {
+	f, err := common.LoadFile(c.file)
+	if err != nil {
+		return fmt.Errorf("load config file: %w", err)
+	}
+
+	var raw map[string]interface{}
+	if _, err = toml.Decode(string(f), &raw); err != nil {
+		return fmt.Errorf("parse toml config: %w", err)
+	}
+
+	raw["scans"] = c.Scans
+	if len(c.Scans) == 0 {
+		delete(raw, "scans")
+	}
+	if c.profileSet {
+		sections, _ := raw["redis"].(map[string]interface{})
+		for name, p := range c.profiles {
+			section, ok := sections[name].(map[string]interface{})
+			if !ok || !p.ownScans {
+				continue
+			}
+			section["scans"] = p.Scans
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf)
+	enc.Indent = ""
+	if err = enc.Encode(raw); err != nil {
+		return fmt.Errorf("encode toml config: %w", err)
+	}
+
+	fi, err := os.Stat(c.file)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.file, buf.Bytes(), fi.Mode())
+}
+
+// profileNames returns the names of the profiles in alphabetical order.
+func (c *config) profileNames() []string {
+	names := make([]string, 0, len(c.profiles))
+	for name := range c.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isProfileSet returns true if the decoded redis section consists of profile sub-sections only.
+func isProfileSet(raw map[string]interface{}) bool {
+	if len(raw) == 0 {
+		return false
+	}
+	for name, v := range raw {
+		if _, ok := v.(map[string]interface{}); !ok || reservedProfiles[name] {
+			return false
+		}
+	}
+	return true
+}
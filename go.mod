@@ -6,4 +6,7 @@ require (
 	github.com/BurntSushi/toml v0.3.1
 	github.com/gizak/termui/v3 v3.1.0
 	github.com/go-redis/redis/v8 v8.10.0
+	github.com/golang/snappy v0.0.4
+	github.com/vmihailenco/msgpack/v5 v5.3.5
+	google.golang.org/protobuf v1.28.1
 )
@@ -10,4 +10,7 @@ type Logger interface {
 
 	// Messages returns all the messages from the logger's buffer.
 	Messages() []string
+
+	// Attach starts collecting messages from the provided channel until it is closed.
+	Attach(<-chan string)
 }
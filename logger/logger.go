@@ -19,6 +19,7 @@ const (
 type logger struct {
 	*widgets.Paragraph
 
+	ctx      context.Context
 	cancel   context.CancelFunc
 	messages []string
 	mtx      sync.Mutex
@@ -32,22 +33,28 @@ func NewLogger(ctx context.Context, channels ...<-chan string) *logger {
 	l := &logger{
 		Paragraph: widgets.NewParagraph(),
 		messages:  make([]string, 0, bufSize),
+		ctx:       ctx,
 		cancel:    cancel,
 	}
 	l.Title = " Messages "
 	l.WrapText = true
 
 	for _, ch := range channels {
-		l.wg.Add(1)
-		go func(c <-chan string) {
-			defer l.wg.Done()
-			l.readChan(ctx, c)
-		}(ch)
+		l.Attach(ch)
 	}
 
 	return l
 }
 
+// Attach implements the Logger interface.
+func (l *logger) Attach(ch <-chan string) {
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		l.readChan(l.ctx, ch)
+	}()
+}
+
 // readChan is a worker goroutine for worker that pushes incoming messages from the provided
 // channel into the buffer.
 func (l *logger) readChan(ctx context.Context, in <-chan string) {
@@ -55,7 +62,11 @@ func (l *logger) readChan(ctx context.Context, in <-chan string) {
 		select {
 		case <-ctx.Done():
 			return
-		case m := <-in:
+		case m, ok := <-in:
+			if !ok {
+				// Channel has been closed by its sender.
+				return
+			}
 			l.push(m)
 		}
 	}
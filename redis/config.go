@@ -1,17 +1,54 @@
 package redis
 
 import (
+	"errors"
+
 	"github.com/milonoir/rv/common"
 )
 
 // Config is the configuration for a Redis server.
 type Config struct {
 	Server       string          `toml:"server"`
-	Password     string          `toml:"password"`
+	Username     Secret          `toml:"username"`
+	Password     Secret          `toml:"password"`
 	DB           int             `toml:"db"`
 	DialTimeout  common.Duration `toml:"dial_timeout"`
 	IdleTimeout  common.Duration `toml:"idle_timeout"`
 	ReadTimeout  common.Duration `toml:"read_timeout"`
 	WriteTimeout common.Duration `toml:"write_timeout"`
 	MaxRetries   int             `toml:"max_retries"`
+
+	// Cluster enables Redis Cluster mode. Addrs is the seed list of cluster nodes; Server is used
+	// as the only seed node when Addrs is empty.
+	Cluster bool     `toml:"cluster"`
+	Addrs   []string `toml:"addrs"`
+
+	// TLS configures encrypted connections to the Redis server(s).
+	TLS *TLSConfig `toml:"tls"`
+
+	// Sentinel enables Redis Sentinel mode with automatic failover when set.
+	Sentinel *SentinelConfig `toml:"sentinel"`
+}
+
+// SentinelConfig is the configuration for a Sentinel-managed Redis server.
+type SentinelConfig struct {
+	MasterName string   `toml:"master_name"`
+	Addrs      []string `toml:"addrs"`
+	Password   Secret   `toml:"password"`
+}
+
+// Validate returns an error if the configuration enables both Sentinel and Cluster mode.
+func (c *Config) Validate() error {
+	if c.Sentinel != nil && c.Cluster {
+		return errors.New("sentinel and cluster modes are mutually exclusive")
+	}
+	return nil
+}
+
+// ClusterAddrs returns the seed list of cluster nodes.
+func (c *Config) ClusterAddrs() []string {
+	if len(c.Addrs) > 0 {
+		return c.Addrs
+	}
+	return []string{c.Server}
 }
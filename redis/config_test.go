@@ -0,0 +1,26 @@
+package redis
+
+import (
+	"testing"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{name: "single server", cfg: Config{Server: "localhost:6379"}},
+		{name: "cluster", cfg: Config{Cluster: true, Addrs: []string{"localhost:7000"}}},
+		{name: "sentinel", cfg: Config{Sentinel: &SentinelConfig{MasterName: "mymaster"}}},
+		{name: "sentinel and cluster", cfg: Config{Cluster: true, Sentinel: &SentinelConfig{MasterName: "mymaster"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
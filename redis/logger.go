@@ -0,0 +1,53 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	logBufSize = 10
+
+	failoverPrefix = "sentinel: new master"
+	sentinelPrefix = "sentinel: "
+	// reconnectPrefix is logged when the subscription to the failover events of the sentinels is
+	// re-established.
+	reconnectPrefix = "redis: discarding bad PubSub connection"
+)
+
+// Logger collects the failover and reconnect events of the Redis client in Sentinel mode. Other internal
+// log messages of the client are dropped, so that they do not drown out these events.
+type Logger struct {
+	messages chan string
+}
+
+// NewLogger returns a fully configured Logger.
+func NewLogger() *Logger {
+	return &Logger{
+		messages: make(chan string, logBufSize),
+	}
+}
+
+// Printf implements the internal.Logging interface of the Redis client.
+func (l *Logger) Printf(_ context.Context, format string, v ...interface{}) {
+	m := fmt.Sprintf(format, v...)
+	switch {
+	case strings.HasPrefix(m, failoverPrefix):
+		m = fmt.Sprintf("[failover](fg:yellow) %s", m)
+	case strings.HasPrefix(m, sentinelPrefix), strings.HasPrefix(m, reconnectPrefix):
+		m = fmt.Sprintf("[sentinel](fg:cyan) %s", m)
+	default:
+		return
+	}
+
+	select {
+	case l.messages <- m:
+	default:
+	}
+}
+
+// Messages implements the common.Messenger interface.
+func (l *Logger) Messages() <-chan string {
+	return l.messages
+}
@@ -0,0 +1,59 @@
+package redis
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLoggerPrintf(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		args   []interface{}
+		want   string
+	}{
+		{
+			name:   "failover",
+			format: "sentinel: new master=%q addr=%q",
+			args:   []interface{}{"mymaster", "10.0.0.2:6379"},
+			want:   `[failover](fg:yellow) sentinel: new master="mymaster" addr="10.0.0.2:6379"`,
+		},
+		{
+			name:   "sentinel error",
+			format: "sentinel: GetMasterAddrByName master=%q failed: %s",
+			args:   []interface{}{"mymaster", "i/o timeout"},
+			want:   `[sentinel](fg:cyan) sentinel: GetMasterAddrByName master="mymaster" failed: i/o timeout`,
+		},
+		{
+			name:   "reconnect",
+			format: "redis: discarding bad PubSub connection: %s",
+			args:   []interface{}{"EOF"},
+			want:   "[sentinel](fg:cyan) redis: discarding bad PubSub connection: EOF",
+		},
+		{
+			name:   "other",
+			format: "Conn has unread data",
+		},
+		{
+			name:   "cluster",
+			format: "ReapStaleConns failed: %s",
+			args:   []interface{}{"EOF"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLogger()
+			l.Printf(context.Background(), tt.format, tt.args...)
+
+			var got string
+			select {
+			case got = <-l.Messages():
+			default:
+			}
+			if got != tt.want {
+				t.Errorf("message = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
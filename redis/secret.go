@@ -0,0 +1,87 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/milonoir/rv/common"
+)
+
+var (
+	secretCommandTimeout = 10 * time.Second
+)
+
+// Secret is a credential. It is either set in plaintext or loaded from an environment variable,
+// a file or the standard output of a helper command.
+//
+// In TOML, a plaintext secret is a string, other sources are set by a table with exactly one of
+// the env, file or command keys.
+type Secret struct {
+	Value   string
+	Env     string
+	File    string
+	Command string
+}
+
+// UnmarshalTOML implements the toml.Unmarshaler interface.
+func (s *Secret) UnmarshalTOML(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		*s = Secret{Value: v}
+		return nil
+	case map[string]interface{}:
+		if len(v) != 1 {
+			return fmt.Errorf("secret must have exactly one source: %v", v)
+		}
+		for source, val := range v {
+			str, ok := val.(string)
+			if !ok {
+				return fmt.Errorf("cannot unmarshal secret %s: %v", source, val)
+			}
+			switch source {
+			case "env":
+				*s = Secret{Env: str}
+			case "file":
+				*s = Secret{File: str}
+			case "command":
+				*s = Secret{Command: str}
+			default:
+				return fmt.Errorf("unknown secret source: %s", source)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("cannot unmarshal %v", src)
+	}
+}
+
+// Resolve returns the value of the secret loaded from its source.
+func (s Secret) Resolve() (string, error) {
+	switch {
+	case s.Env != "":
+		v, ok := os.LookupEnv(s.Env)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", s.Env)
+		}
+		return v, nil
+	case s.File != "":
+		b, err := common.LoadFile(s.File)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+	case s.Command != "":
+		ctx, cancel := context.WithTimeout(context.Background(), secretCommandTimeout)
+		defer cancel()
+		b, err := common.RunCommand(ctx, s.Command)
+		if err != nil {
+			return "", fmt.Errorf("run %q: %w", s.Command, err)
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+	default:
+		return s.Value, nil
+	}
+}
@@ -0,0 +1,90 @@
+package redis
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestSecretUnmarshalTOML(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		want    Secret
+		wantErr bool
+	}{
+		{name: "plaintext", doc: `password = "secret"`, want: Secret{Value: "secret"}},
+		{name: "env", doc: `password = { env = "REDIS_PASSWORD" }`, want: Secret{Env: "REDIS_PASSWORD"}},
+		{name: "file", doc: `password = { file = "/run/secrets/redis" }`, want: Secret{File: "/run/secrets/redis"}},
+		{name: "command", doc: `password = { command = "pass show redis" }`, want: Secret{Command: "pass show redis"}},
+		{name: "no source", doc: `password = {}`, wantErr: true},
+		{name: "several sources", doc: `password = { env = "REDIS_PASSWORD", file = "/run/secrets/redis" }`, wantErr: true},
+		{name: "unknown source", doc: `password = { vault = "redis" }`, wantErr: true},
+		{name: "source not a string", doc: `password = { env = 1 }`, wantErr: true},
+		{name: "not a string", doc: `password = 1`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg struct {
+				Password Secret `toml:"password"`
+			}
+			_, err := toml.Decode(tt.doc, &cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.Password != tt.want {
+				t.Errorf("secret = %+v, want %+v", cfg.Password, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecretResolve(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "secret")
+	if err := ioutil.WriteFile(file, []byte("from-file\r\n\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Setenv("RV_TEST_SECRET", "from-env\n"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("RV_TEST_SECRET")
+
+	tests := []struct {
+		name    string
+		secret  Secret
+		unix    bool
+		want    string
+		wantErr bool
+	}{
+		{name: "plaintext", secret: Secret{Value: "plain\n"}, want: "plain\n"},
+		{name: "empty", secret: Secret{}, want: ""},
+		{name: "env", secret: Secret{Env: "RV_TEST_SECRET"}, want: "from-env\n"},
+		{name: "unset env", secret: Secret{Env: "RV_TEST_SECRET_UNSET"}, wantErr: true},
+		{name: "file with trailing newlines", secret: Secret{File: file}, want: "from-file"},
+		{name: "missing file", secret: Secret{File: filepath.Join(dir, "missing")}, wantErr: true},
+		{name: "command with trailing newline", secret: Secret{Command: "echo from-command"}, want: "from-command"},
+		{name: "command keeps inner newlines", secret: Secret{Command: "printf 'a\\nb\\n'"}, unix: true, want: "a\nb"},
+		{name: "failing command", secret: Secret{Command: "exit 3"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.unix && runtime.GOOS == "windows" {
+				t.Skip("needs a POSIX shell")
+			}
+			got, err := tt.secret.Resolve()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
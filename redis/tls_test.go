@@ -0,0 +1,243 @@
+package redis
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testPKI holds the files of a CA, a server certificate for redis.test and a client certificate.
+type testPKI struct {
+	caFile, certFile, keyFile string
+	server                    tls.Certificate
+	pool                      *x509.CertPool
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	dir := t.TempDir()
+
+	caKey, caDER := createCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "rv test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, nil)
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serverKey, serverDER := createCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "redis.test"},
+		DNSNames:     []string{"redis.test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca, caKey)
+
+	clientKey, clientDER := createCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "rv"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, caKey)
+
+	p := &testPKI{
+		caFile:   filepath.Join(dir, "ca.pem"),
+		certFile: filepath.Join(dir, "client.pem"),
+		keyFile:  filepath.Join(dir, "client-key.pem"),
+		server:   tls.Certificate{Certificate: [][]byte{serverDER}, PrivateKey: serverKey},
+		pool:     x509.NewCertPool(),
+	}
+	p.pool.AddCert(ca)
+	writePEM(t, p.caFile, "CERTIFICATE", caDER)
+	writePEM(t, p.certFile, "CERTIFICATE", clientDER)
+	keyDER, err := x509.MarshalECPrivateKey(clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, p.keyFile, "EC PRIVATE KEY", keyDER)
+	return p
+}
+
+// createCert returns the key and the certificate of the template signed by the parent, or self-signed
+// if parent is nil.
+func createCert(t *testing.T, tmpl, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*ecdsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, der
+}
+
+func writePEM(t *testing.T, file, typ string, der []byte) {
+	t.Helper()
+	if err := ioutil.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// handshake completes a TLS handshake against a local TLS-terminated stand-in of a Redis server, and
+// returns the errors of the client and of the server.
+func handshake(t *testing.T, p *testPKI, clientAuth tls.ClientAuthType, cfg *tls.Config) (error, error) {
+	t.Helper()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{p.server},
+		ClientAuth:   clientAuth,
+		ClientCAs:    p.pool,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	served := make(chan error, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			served <- err
+			return
+		}
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if err = conn.(*tls.Conn).Handshake(); err != nil {
+			served <- err
+			return
+		}
+		// Reply to the ping once the handshake, including the client certificate, has been verified.
+		buf := make([]byte, 64)
+		n, err := conn.Read(buf)
+		if err == nil {
+			_, err = conn.Write(buf[:n])
+		}
+		served <- err
+	}()
+
+	conn, err := net.DialTimeout("tcp", ln.Addr().String(), 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	c := tls.Client(conn, cfg)
+	clientErr := c.Handshake()
+	if clientErr == nil {
+		if _, clientErr = c.Write([]byte("PING\r\n")); clientErr == nil {
+			_, clientErr = c.Read(make([]byte, 64))
+		}
+	}
+	c.Close()
+	return clientErr, <-served
+}
+
+func TestTLSConfigBuild(t *testing.T) {
+	p := newTestPKI(t)
+
+	tests := []struct {
+		name       string
+		cfg        TLSConfig
+		clientAuth tls.ClientAuthType
+		wantErr    bool
+	}{
+		{
+			name: "CA and server name",
+			cfg:  TLSConfig{Enabled: true, CAFile: p.caFile, ServerName: "redis.test"},
+		},
+		{
+			name:    "unknown CA",
+			cfg:     TLSConfig{Enabled: true, ServerName: "redis.test"},
+			wantErr: true,
+		},
+		{
+			name:    "server name mismatch",
+			cfg:     TLSConfig{Enabled: true, CAFile: p.caFile, ServerName: "other.test"},
+			wantErr: true,
+		},
+		{
+			name: "insecure skip verify",
+			cfg:  TLSConfig{Enabled: true, InsecureSkipVerify: true},
+		},
+		{
+			name:       "mutual TLS",
+			cfg:        TLSConfig{Enabled: true, CAFile: p.caFile, ServerName: "redis.test", CertFile: p.certFile, KeyFile: p.keyFile},
+			clientAuth: tls.RequireAndVerifyClientCert,
+		},
+		{
+			name:       "mutual TLS without client certificate",
+			cfg:        TLSConfig{Enabled: true, CAFile: p.caFile, ServerName: "redis.test"},
+			clientAuth: tls.RequireAndVerifyClientCert,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := tt.cfg.Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			clientErr, serverErr := handshake(t, p, tt.clientAuth, cfg)
+			if got := clientErr != nil || serverErr != nil; got != tt.wantErr {
+				t.Errorf("handshake client error = %v, server error = %v, want error %v", clientErr, serverErr, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTLSConfigBuildErrors(t *testing.T) {
+	p := newTestPKI(t)
+
+	tests := []struct {
+		name string
+		cfg  *TLSConfig
+		nil  bool
+		err  bool
+	}{
+		{name: "nil", cfg: nil, nil: true},
+		{name: "disabled", cfg: &TLSConfig{CAFile: p.caFile}, nil: true},
+		{name: "missing CA file", cfg: &TLSConfig{Enabled: true, CAFile: filepath.Join(t.TempDir(), "missing.pem")}, err: true},
+		{name: "CA file without certificates", cfg: &TLSConfig{Enabled: true, CAFile: p.keyFile}, err: true},
+		{name: "cert without key", cfg: &TLSConfig{Enabled: true, CertFile: p.certFile}, err: true},
+		{name: "key without cert", cfg: &TLSConfig{Enabled: true, KeyFile: p.keyFile}, err: true},
+		{name: "mismatched key pair", cfg: &TLSConfig{Enabled: true, CertFile: p.caFile, KeyFile: p.keyFile}, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := tt.cfg.Build()
+			if (err != nil) != tt.err {
+				t.Fatalf("Build() error = %v, want error %v", err, tt.err)
+			}
+			if (cfg == nil) != (tt.nil || tt.err) {
+				t.Errorf("Build() = %v, want nil %v", cfg, tt.nil || tt.err)
+			}
+		})
+	}
+}
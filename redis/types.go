@@ -10,6 +10,10 @@ const (
 	TypeSet       = DataType("set")
 	TypeSortedSet = DataType("zset")
 	TypeHash      = DataType("hash")
+	TypeStream    = DataType("stream")
+
+	// TypeAuto is not a Redis type. It configures scanners to detect the type of each matching key.
+	TypeAuto = DataType("auto")
 )
 
 type DataType string
@@ -20,7 +24,7 @@ func (dt *DataType) UnmarshalTOML(src interface{}) error {
 		return fmt.Errorf("cannot unmarshal %v", src)
 	}
 	switch v := DataType(s); v {
-	case TypeKey, TypeList, TypeSet, TypeSortedSet, TypeHash:
+	case TypeKey, TypeList, TypeSet, TypeSortedSet, TypeHash, TypeStream, TypeAuto:
 		*dt = v
 		return nil
 	default:
@@ -31,3 +35,20 @@ func (dt *DataType) UnmarshalTOML(src interface{}) error {
 func (dt DataType) String() string {
 	return string(dt)
 }
+
+// RedisType returns the name of the type as used by the Redis TYPE command and the TYPE option of
+// the SCAN command.
+func (dt DataType) RedisType() string {
+	if dt == TypeKey {
+		return "string"
+	}
+	return string(dt)
+}
+
+// ParseRedisType returns the DataType of a reply of the Redis TYPE command.
+func ParseRedisType(s string) DataType {
+	if s == "string" {
+		return TypeKey
+	}
+	return DataType(s)
+}
@@ -0,0 +1,209 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/milonoir/rv/common"
+)
+
+const (
+	ruleAbove  = "above"
+	ruleBelow  = "below"
+	ruleGrowth = "growth_rate"
+)
+
+var (
+	alertTimeout = 10 * time.Second
+)
+
+// rule is the state of an alert rule evaluated against the last sample.
+type rule struct {
+	name      string
+	firing    bool
+	threshold float64
+	value     float64
+}
+
+// message returns the human readable description of the firing rule.
+func (rl rule) message() string {
+	switch rl.name {
+	case ruleAbove:
+		return fmt.Sprintf("key count %s is above %s", formatFloat(rl.value), formatFloat(rl.threshold))
+	case ruleBelow:
+		return fmt.Sprintf("key count %s is below %s", formatFloat(rl.value), formatFloat(rl.threshold))
+	default:
+		return fmt.Sprintf("key count grows by %s keys/min, faster than %s", formatFloat(rl.value), formatFloat(rl.threshold))
+	}
+}
+
+// alertPayload is the JSON payload POSTed to the alert webhook.
+type alertPayload struct {
+	Scanner   string    `json:"scanner"`
+	Pattern   string    `json:"pattern"`
+	Rule      string    `json:"rule"`
+	Threshold float64   `json:"threshold"`
+	Value     float64   `json:"value"`
+	Count     int       `json:"count"`
+	Time      time.Time `json:"time"`
+	Message   string    `json:"message"`
+}
+
+// rules evaluates the configured alert rules against the last sample of the history.
+func (w *worker) rules(history []Sample) []rule {
+	last := history[len(history)-1]
+	count := float64(last.Count)
+
+	var rules []rule
+	if w.AlertAbove != nil {
+		t := float64(*w.AlertAbove)
+		rules = append(rules, rule{name: ruleAbove, firing: count > t, threshold: t, value: count})
+	}
+	if w.AlertBelow != nil {
+		t := float64(*w.AlertBelow)
+		rules = append(rules, rule{name: ruleBelow, firing: count < t, threshold: t, value: count})
+	}
+	if w.AlertGrowthRate > 0 && len(history) > 1 {
+		prev := history[len(history)-2]
+		if d := last.Time.Sub(prev.Time).Minutes(); d > 0 {
+			rate := float64(last.Count-prev.Count) / d
+			rules = append(rules, rule{name: ruleGrowth, firing: rate > w.AlertGrowthRate, threshold: w.AlertGrowthRate, value: rate})
+		}
+	}
+	return rules
+}
+
+// checkAlerts evaluates the alert rules and notifies about the rules which started or stopped firing.
+// Only called by Run, after complete passes which have not been truncated.
+func (w *worker) checkAlerts(history []Sample) {
+	if len(history) == 0 {
+		return
+	}
+
+	alerting := false
+	for _, rl := range w.rules(history) {
+		alerting = alerting || rl.firing
+		if rl.firing == w.alerts[rl.name] {
+			continue
+		}
+		w.alerts[rl.name] = rl.firing
+
+		if !rl.firing {
+			w.sendAlert(fmt.Sprintf("[alert cleared](fg:green) (worker: %s): %s", w.name, rl.name))
+			continue
+		}
+		m := rl.message()
+		w.sendAlert(fmt.Sprintf("[ALERT](fg:white,bg:red) (worker: %s): %s", w.name, m))
+		w.notify(rl, history[len(history)-1], m)
+	}
+
+	w.mtx.Lock()
+	w.alerting = alerting
+	w.mtx.Unlock()
+}
+
+// sendAlert queues the alert message and tries sending the queued messages to the error channel.
+// Unlike errors, alert messages are not dropped when the channel is full: they are sent again by the
+// next run.
+func (w *worker) sendAlert(m string) {
+	w.queued = append(w.queued, m)
+	w.flushAlerts()
+}
+
+// flushAlerts tries sending the queued alert messages to the error channel, in order, without blocking.
+func (w *worker) flushAlerts() {
+	for len(w.queued) > 0 {
+		select {
+		case w.err <- w.queued[0]:
+			w.queued = w.queued[1:]
+		default:
+			return
+		}
+	}
+}
+
+// notify runs the alert command and calls the alert webhook in the background.
+func (w *worker) notify(rl rule, sample Sample, msg string) {
+	if w.AlertCommand == "" && w.AlertWebhook == "" {
+		return
+	}
+
+	p := alertPayload{
+		Scanner:   w.name,
+		Pattern:   w.Config.Pattern,
+		Rule:      rl.name,
+		Threshold: rl.threshold,
+		Value:     rl.value,
+		Count:     sample.Count,
+		Time:      sample.Time,
+		Message:   msg,
+	}
+
+	w.notifications.Add(1)
+	go func() {
+		defer w.notifications.Done()
+
+		ctx, cancel := context.WithTimeout(w.ctx, alertTimeout)
+		defer cancel()
+
+		if w.AlertCommand != "" {
+			if _, err := common.RunCommand(ctx, w.AlertCommand, p.env()...); err != nil {
+				w.sendErr(fmt.Errorf("alert command: %w", err))
+			}
+		}
+		if w.AlertWebhook != "" {
+			if err := postAlert(ctx, w.AlertWebhook, p); err != nil {
+				w.sendErr(fmt.Errorf("alert webhook: %w", err))
+			}
+		}
+	}()
+}
+
+// env returns the payload as environment variables for the alert command.
+func (p alertPayload) env() []string {
+	return []string{
+		"RV_SCANNER=" + p.Scanner,
+		"RV_PATTERN=" + p.Pattern,
+		"RV_RULE=" + p.Rule,
+		"RV_THRESHOLD=" + formatFloat(p.Threshold),
+		"RV_VALUE=" + formatFloat(p.Value),
+		"RV_COUNT=" + strconv.Itoa(p.Count),
+		"RV_MESSAGE=" + p.Message,
+	}
+}
+
+// postAlert POSTs the payload to the webhook URL.
+func postAlert(ctx context.Context, url string, p alertPayload) error {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+	return nil
+}
+
+// formatFloat formats the number with at most two decimals.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,133 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPostAlert(t *testing.T) {
+	p := alertPayload{
+		Scanner:   "jobs",
+		Pattern:   "job:*",
+		Rule:      ruleAbove,
+		Threshold: 10,
+		Value:     12,
+		Count:     12,
+		Time:      time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC),
+		Message:   "key count 12 is above 10",
+	}
+
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "ok", status: http.StatusOK},
+		{name: "no content", status: http.StatusNoContent},
+		{name: "redirect", status: http.StatusMultipleChoices, wantErr: true},
+		{name: "server error", status: http.StatusInternalServerError, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				method, contentType string
+				got                 alertPayload
+				decodeErr           error
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				method, contentType = req.Method, req.Header.Get("Content-Type")
+				decodeErr = json.NewDecoder(req.Body).Decode(&got)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			err := postAlert(context.Background(), srv.URL, p)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("postAlert() error = %v, want error %v", err, tt.wantErr)
+			}
+			if method != http.MethodPost {
+				t.Errorf("method = %s, want %s", method, http.MethodPost)
+			}
+			if contentType != "application/json" {
+				t.Errorf("content type = %s, want application/json", contentType)
+			}
+			if decodeErr != nil {
+				t.Fatalf("decode payload: %v", decodeErr)
+			}
+			if !got.Time.Equal(p.Time) {
+				t.Errorf("payload time = %v, want %v", got.Time, p.Time)
+			}
+			got.Time = p.Time
+			if got != p {
+				t.Errorf("payload = %+v, want %+v", got, p)
+			}
+		})
+	}
+}
+
+func TestPostAlertPayloadFields(t *testing.T) {
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_ = json.NewDecoder(req.Body).Decode(&body)
+	}))
+	defer srv.Close()
+
+	if err := postAlert(context.Background(), srv.URL, alertPayload{Rule: ruleGrowth}); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"scanner", "pattern", "rule", "threshold", "value", "count", "time", "message"} {
+		if _, ok := body[field]; !ok {
+			t.Errorf("payload has no %q field: %v", field, body)
+		}
+	}
+}
+
+func TestPostAlertUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	if err := postAlert(context.Background(), url, alertPayload{}); err == nil {
+		t.Error("postAlert() error = nil, want error")
+	}
+}
+
+func TestCheckAlertsNotDropped(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	above := 10
+	w := newWorker(ctx, nil, "jobs", &Config{Pattern: "job:*", AlertAbove: &above}).(*worker)
+	// Fill the error channel, as if the messages had not been received yet.
+	for i := 0; i < errBufSize; i++ {
+		w.sendMsg("error")
+	}
+
+	// Does not block on the full channel.
+	w.checkAlerts([]Sample{{Time: time.Now(), Count: 12}})
+	for i := 0; i < errBufSize; i++ {
+		if m := <-w.err; strings.Contains(m, "ALERT") {
+			t.Fatalf("alert message sent to the full channel: %s", m)
+		}
+	}
+
+	// Sent by the next run.
+	w.flushAlerts()
+	select {
+	case m := <-w.err:
+		if !strings.Contains(m, "ALERT") {
+			t.Errorf("message = %s, want alert", m)
+		}
+	default:
+		t.Error("alert message dropped")
+	}
+	if !w.Alerting() {
+		t.Error("Alerting() = false, want true")
+	}
+}
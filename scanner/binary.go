@@ -0,0 +1,103 @@
+package scanner
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/milonoir/rv/common"
+)
+
+const (
+	// dumpWidth is the number of bytes in a row of the hex dump.
+	dumpWidth = 16
+	// maxDumpSize limits the number of bytes shown in a hex dump.
+	maxDumpSize = 64 << 10
+)
+
+// isBinary returns true if the value is not valid UTF-8 or contains control characters other than
+// whitespace.
+func isBinary(value string) bool {
+	if !utf8.ValidString(value) {
+		return true
+	}
+	for _, r := range value {
+		if unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r' {
+			return true
+		}
+	}
+	return false
+}
+
+// escape returns the value in a single line with the non-printable characters and invalid bytes escaped
+// like in Go string literals, and the square brackets replaced like by common.EscapeMarkup.
+func escape(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); {
+		r, size := utf8.DecodeRuneInString(value[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&b, `\x%02x`, value[i])
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '[' || r == ']':
+			b.WriteString(common.EscapeMarkup(string(r)))
+		case unicode.IsPrint(r):
+			b.WriteRune(r)
+		case r < utf8.RuneSelf:
+			fmt.Fprintf(&b, `\x%02x`, r)
+		default:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// hexDump returns the offset, hex and ASCII columns of the value like xxd, a row for every dumpWidth
+// bytes. Non-printable characters and square brackets are shown as dots in the ASCII column. Values
+// larger than maxDumpSize are truncated.
+func hexDump(value string) []string {
+	n := len(value)
+	if n > maxDumpSize {
+		n = maxDumpSize
+	}
+
+	lines := make([]string, 0, n/dumpWidth+2)
+	for offset := 0; offset < n; offset += dumpWidth {
+		end := offset + dumpWidth
+		if end > n {
+			end = n
+		}
+		row := value[offset:end]
+
+		var hex, ascii strings.Builder
+		for i := 0; i < dumpWidth; i++ {
+			if i > 0 && i%2 == 0 {
+				hex.WriteByte(' ')
+			}
+			if i >= len(row) {
+				hex.WriteString("  ")
+				continue
+			}
+			fmt.Fprintf(&hex, "%02x", row[i])
+			// Square brackets would be parsed as style markup by termui.
+			if c := row[i]; c >= 0x20 && c < 0x7f && c != '[' && c != ']' {
+				ascii.WriteByte(c)
+			} else {
+				ascii.WriteByte('.')
+			}
+		}
+		lines = append(lines, fmt.Sprintf("[%08x:](fg:cyan) %s  %s", offset, hex.String(), ascii.String()))
+	}
+
+	if len(value) > n {
+		lines = append(lines, fmt.Sprintf("[… %s more](fg:yellow)", formatBytes(int64(len(value)-n))))
+	}
+	return lines
+}
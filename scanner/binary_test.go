@@ -0,0 +1,69 @@
+package scanner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{value: "", want: false},
+		{value: "plain text", want: false},
+		{value: "tab\tnew\nline\r", want: false},
+		{value: "ключ", want: false},
+		{value: "nul\x00", want: true},
+		{value: "bell\x07", want: true},
+		{value: "invalid \xff", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := isBinary(tt.value); got != tt.want {
+			t.Errorf("isBinary(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "plain", want: "plain"},
+		{value: "a\tb\nc\rd", want: `a\tb\nc\rd`},
+		{value: "nul\x00\x7f", want: `nul\x00\x7f`},
+		{value: "invalid\xff\xfe", want: `invalid\xff\xfe`},
+		{value: "zero\u200bwidth", want: `zero\u200bwidth`},
+		{value: "ключ", want: "ключ"},
+		{value: "[x](fg:red)", want: "⁅x⁆(fg:red)"},
+		{value: "unbalanced ]\x00[", want: `unbalanced ⁆\x00⁅`},
+	}
+
+	for _, tt := range tests {
+		if got := escape(tt.value); got != tt.want {
+			t.Errorf("escape(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestHexDump(t *testing.T) {
+	got := hexDump("[a]\x00bcdefghijklmnopq")
+	want := []string{
+		"[00000000:](fg:cyan) 5b61 5d00 6263 6465 6667 6869 6a6b 6c6d  .a..bcdefghijklm",
+		"[00000010:](fg:cyan) 6e6f 7071                                nopq",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("hexDump() = %q, want %q", got, want)
+	}
+
+	got = hexDump(strings.Repeat("x", maxDumpSize+10))
+	if n := len(got); n != maxDumpSize/dumpWidth+1 {
+		t.Fatalf("len(hexDump()) = %d, want %d", n, maxDumpSize/dumpWidth+1)
+	}
+	if last, want := got[len(got)-1], "[… 10B more](fg:yellow)"; last != want {
+		t.Errorf("last row = %q, want %q", last, want)
+	}
+}
@@ -0,0 +1,84 @@
+package scanner
+
+import (
+	"fmt"
+	"time"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+)
+
+const (
+	// chartAxesWidth is the width of the y axis labels and the y axis of the plot.
+	chartAxesWidth = 5
+)
+
+// chart renders the key count history of a worker into a widgets.Plot.
+type chart struct {
+	*widgets.Plot
+}
+
+// NewChart returns a fully configured chart.
+func NewChart() *chart {
+	c := &chart{
+		Plot: widgets.NewPlot(),
+	}
+	c.LineColors = []ui.Color{ui.ColorCyan}
+	c.AxesColor = ui.ColorWhite
+
+	return c
+}
+
+// Update implements the common.Widget interface.
+func (c *chart) Update() {
+	ui.Render(c)
+}
+
+// Resize implements the common.Widget interface.
+func (c *chart) Resize(x1, y1, x2, y2 int) {
+	c.SetRect(x1, y1, x2, y2)
+}
+
+// Close implements the common.Widget interface.
+func (c *chart) Close() {}
+
+// SetHistory implements the Chart interface.
+// Only the most recent samples fitting into the plot are charted.
+func (c *chart) SetHistory(name string, history []Sample) {
+	if n := c.Inner.Dx() - chartAxesWidth; n > 0 && len(history) > n {
+		history = history[len(history)-n:]
+	}
+
+	// The line chart needs at least two data points.
+	if len(history) < 2 {
+		c.Title = fmt.Sprintf(" History: %s - waiting for samples ", name)
+		c.Data = [][]float64{}
+		c.MaxVal = 1
+		return
+	}
+
+	data := make([]float64, len(history))
+	min, max := history[0].Count, history[0].Count
+	for i, h := range history {
+		data[i] = float64(h.Count)
+		if h.Count < min {
+			min = h.Count
+		}
+		if h.Count > max {
+			max = h.Count
+		}
+	}
+
+	c.Data = [][]float64{data}
+	c.MaxVal = 0
+	if max == 0 {
+		c.MaxVal = 1
+	}
+
+	first, last := history[0], history[len(history)-1]
+	c.Title = fmt.Sprintf(
+		" History: %s - last: %d  min: %d  max: %d  (%s to %s) ",
+		name, last.Count, min, max,
+		first.Time.Format(time.Kitchen), last.Time.Format(time.Kitchen),
+	)
+}
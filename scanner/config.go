@@ -12,9 +12,41 @@ type Config struct {
 	Pattern  string          `toml:"pattern"`
 	Type     r.DataType      `toml:"type"`
 	Interval common.Duration `toml:"interval"`
+
+	// Count is the COUNT hint of the Redis scan command. The server default is used if it is 0.
+	Count int64 `toml:"count,omitzero"`
+	// MaxKeys limits the number of keys collected by a scan. Scans are not limited if it is 0.
+	MaxKeys int `toml:"max_keys,omitzero"`
+
+	// Incremental spreads a pass over the keyspace across intervals. Each interval executes at most
+	// Iterations scan commands and the result is published when the pass is complete.
+	Incremental bool `toml:"incremental,omitempty"`
+	Iterations  int  `toml:"iterations,omitzero"`
+
+	// Alert rules. An alert fires when the key count goes above AlertAbove or below AlertBelow,
+	// or grows faster than AlertGrowthRate keys per minute between two scans.
+	AlertAbove      *int    `toml:"alert_above"`
+	AlertBelow      *int    `toml:"alert_below"`
+	AlertGrowthRate float64 `toml:"alert_growth_rate,omitzero"`
+	// AlertCommand is a shell command and AlertWebhook is a URL to which a JSON payload is POSTed
+	// when an alert fires.
+	AlertCommand string `toml:"alert_command,omitempty"`
+	AlertWebhook string `toml:"alert_webhook,omitempty"`
+
+	// Decoders is the pipeline of decoders applied to the values of the keys in the viewer, e.g.
+	// ["base64", "gzip", "msgpack"]. Protobuf messages are decoded by "protobuf:<full message name>".
+	Decoders []string `toml:"decoders,omitempty"`
+}
+
+// iterations returns the number of scan iterations per interval in incremental mode.
+func (c Config) iterations() int {
+	if c.Iterations > 0 {
+		return c.Iterations
+	}
+	return defaultIterations
 }
 
-// IsSingle implements the Worker interface.
+// IsSingle returns true if the pattern matches a single Redis key.
 func (c Config) IsSingle() bool {
 	return !strings.Contains(c.Pattern, "*")
 }
@@ -0,0 +1,323 @@
+package scanner
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/golang/snappy"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	// protobufPrefix is the prefix of the protobuf decoder names, followed by the full name of the message.
+	protobufPrefix = "protobuf:"
+	// maxDecodedSize limits the size of decompressed values.
+	maxDecodedSize = 16 << 20
+)
+
+var (
+	// builtinDecoders are the decoders which do not need any configuration, in the order they are cycled.
+	builtinDecoders = []Decoder{
+		decoderFunc{"base64", decodeBase64},
+		decoderFunc{"gzip", decodeGzip},
+		decoderFunc{"zlib", decodeZlib},
+		decoderFunc{"snappy", decodeSnappy},
+		decoderFunc{"msgpack", decodeMsgpack},
+	}
+
+	// snappyStreamHeader is the stream identifier chunk of the snappy framing format.
+	snappyStreamHeader = []byte("\xff\x06\x00\x00sNaPpY")
+
+	// errDecodedSize is returned if a decompressed value is larger than maxDecodedSize.
+	errDecodedSize = fmt.Errorf("decoded value is larger than %s", formatBytes(maxDecodedSize))
+)
+
+// Decoder decodes the stored representation of a value.
+type Decoder interface {
+	// Name returns the name of the decoder as used in the configuration.
+	Name() string
+
+	// Decode returns the decoded value.
+	Decode([]byte) ([]byte, error)
+}
+
+// decoderFunc is a Decoder implemented by a function.
+type decoderFunc struct {
+	name   string
+	decode func([]byte) ([]byte, error)
+}
+
+// Name implements the Decoder interface.
+func (d decoderFunc) Name() string {
+	return d.name
+}
+
+// Decode implements the Decoder interface.
+func (d decoderFunc) Decode(b []byte) ([]byte, error) {
+	return d.decode(b)
+}
+
+// Pipeline is a sequence of decoders, each decoding the output of the previous one.
+type Pipeline []Decoder
+
+// String implements the fmt.Stringer interface.
+func (p Pipeline) String() string {
+	if len(p) == 0 {
+		return "none"
+	}
+	names := make([]string, len(p))
+	for i, d := range p {
+		names[i] = d.Name()
+	}
+	return strings.Join(names, " > ")
+}
+
+// Decode returns the value decoded by every decoder of the pipeline.
+func (p Pipeline) Decode(value string) (string, error) {
+	b := []byte(value)
+	for _, d := range p {
+		var err error
+		if b, err = d.Decode(b); err != nil {
+			return "", fmt.Errorf("%s: %w", d.Name(), err)
+		}
+	}
+	return string(b), nil
+}
+
+// Decoders builds decoder pipelines by name. Protobuf messages are looked up in a descriptor set.
+type Decoders struct {
+	types    *protoregistry.Types
+	messages []string
+}
+
+// NewDecoders returns the decoders with the protobuf messages of the descriptor set file, as written by
+// protoc --descriptor_set_out --include_imports. Protobuf messages cannot be decoded if file is empty.
+func NewDecoders(file string) (*Decoders, error) {
+	d := &Decoders{
+		types: new(protoregistry.Types),
+	}
+	if file == "" {
+		return d, nil
+	}
+
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read descriptor set: %w", err)
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err = proto.Unmarshal(b, &set); err != nil {
+		return nil, fmt.Errorf("parse descriptor set: %w", err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("parse descriptor set: %w", err)
+	}
+
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		err = d.register(fd.Messages(), fd.Package() != "google.protobuf")
+		return err == nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("register messages: %w", err)
+	}
+	sort.Strings(d.messages)
+
+	return d, nil
+}
+
+// register registers the message types and their nested types, so that they can be decoded by name. If
+// preset is true, the messages are also added to the presets cycled by NextDecoder.
+func (d *Decoders) register(mds protoreflect.MessageDescriptors, preset bool) error {
+	for i := 0; i < mds.Len(); i++ {
+		md := mds.Get(i)
+		if md.IsMapEntry() {
+			continue
+		}
+		if err := d.types.RegisterMessage(dynamicpb.NewMessageType(md)); err != nil {
+			return err
+		}
+		if preset {
+			d.messages = append(d.messages, string(md.FullName()))
+		}
+		if err := d.register(md.Messages(), preset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pipeline returns the pipeline of the named decoders.
+func (d *Decoders) Pipeline(names []string) (Pipeline, error) {
+	p := make(Pipeline, 0, len(names))
+	for _, name := range names {
+		dec, err := d.decoder(name)
+		if err != nil {
+			return nil, err
+		}
+		p = append(p, dec)
+	}
+	return p, nil
+}
+
+// Presets returns the single decoder pipelines to cycle through: the builtin decoders, then a protobuf
+// decoder for every message of the descriptor set.
+func (d *Decoders) Presets() []Pipeline {
+	presets := make([]Pipeline, 0, len(builtinDecoders)+len(d.messages))
+	for _, dec := range builtinDecoders {
+		presets = append(presets, Pipeline{dec})
+	}
+	for _, name := range d.messages {
+		dec, _ := d.decoder(protobufPrefix + name)
+		presets = append(presets, Pipeline{dec})
+	}
+	return presets
+}
+
+// decoder returns the named decoder.
+func (d *Decoders) decoder(name string) (Decoder, error) {
+	if strings.HasPrefix(name, protobufPrefix) {
+		message := strings.TrimPrefix(name, protobufPrefix)
+		mt, err := d.types.FindMessageByName(protoreflect.FullName(message))
+		if err != nil {
+			return nil, fmt.Errorf("unknown protobuf message: %s", message)
+		}
+		return &protobufDecoder{mt: mt, types: d.types}, nil
+	}
+
+	for _, dec := range builtinDecoders {
+		if dec.Name() == name {
+			return dec, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown decoder: %s", name)
+}
+
+// protobufDecoder decodes protobuf messages of a type into JSON.
+type protobufDecoder struct {
+	mt    protoreflect.MessageType
+	types *protoregistry.Types
+}
+
+// Name implements the Decoder interface.
+func (d *protobufDecoder) Name() string {
+	return protobufPrefix + string(d.mt.Descriptor().FullName())
+}
+
+// Decode implements the Decoder interface.
+func (d *protobufDecoder) Decode(b []byte) ([]byte, error) {
+	m := d.mt.New().Interface()
+	if err := (proto.UnmarshalOptions{Resolver: d.types}).Unmarshal(b, m); err != nil {
+		return nil, err
+	}
+	return protojson.MarshalOptions{Resolver: d.types}.Marshal(m)
+}
+
+// decodeBase64 decodes standard or URL-safe base64, with or without padding.
+func decodeBase64(b []byte) ([]byte, error) {
+	s := strings.TrimSpace(string(b))
+	enc := base64.StdEncoding
+	if strings.ContainsAny(s, "-_") {
+		enc = base64.URLEncoding
+	}
+	if !strings.HasSuffix(s, "=") {
+		enc = enc.WithPadding(base64.NoPadding)
+	}
+	return enc.DecodeString(s)
+}
+
+func decodeGzip(b []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return readLimited(zr)
+}
+
+func decodeZlib(b []byte) ([]byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return readLimited(zr)
+}
+
+// decodeSnappy decodes both the block and the framing format of snappy.
+func decodeSnappy(b []byte) ([]byte, error) {
+	if bytes.HasPrefix(b, snappyStreamHeader) {
+		return readLimited(snappy.NewReader(bytes.NewReader(b)))
+	}
+	n, err := snappy.DecodedLen(b)
+	if err != nil {
+		return nil, err
+	}
+	if n > maxDecodedSize {
+		return nil, errDecodedSize
+	}
+	return snappy.Decode(nil, b)
+}
+
+// readLimited reads the decompressed value up to maxDecodedSize.
+func readLimited(r io.Reader) ([]byte, error) {
+	b, err := ioutil.ReadAll(io.LimitReader(r, maxDecodedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxDecodedSize {
+		return nil, errDecodedSize
+	}
+	return b, nil
+}
+
+// decodeMsgpack decodes a MessagePack value into JSON.
+func decodeMsgpack(b []byte) ([]byte, error) {
+	dec := msgpack.NewDecoder(bytes.NewReader(b))
+	dec.SetMapDecoder(func(d *msgpack.Decoder) (interface{}, error) {
+		return d.DecodeUntypedMap()
+	})
+	v, err := dec.DecodeInterface()
+	if err != nil {
+		return nil, err
+	}
+	if _, err = dec.DecodeInterface(); err != io.EOF {
+		return nil, errors.New("trailing data after MessagePack value")
+	}
+	return json.Marshal(jsonValue(v))
+}
+
+// jsonValue converts the maps with non-string keys of a decoded MessagePack value, which cannot be
+// marshaled into JSON.
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = jsonValue(e)
+		}
+		return m
+	case []interface{}:
+		for i := range v {
+			v[i] = jsonValue(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
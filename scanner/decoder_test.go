@@ -0,0 +1,377 @@
+package scanner
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestBuiltinDecoders(t *testing.T) {
+	tests := []struct {
+		name    string
+		decoder string
+		in      []byte
+		want    string
+	}{
+		{name: "base64 std", decoder: "base64", in: []byte(base64.StdEncoding.EncodeToString([]byte("hello?>"))), want: "hello?>"},
+		{name: "base64 url", decoder: "base64", in: []byte(base64.URLEncoding.EncodeToString([]byte("hello?>"))), want: "hello?>"},
+		{name: "base64 raw", decoder: "base64", in: []byte(base64.RawStdEncoding.EncodeToString([]byte("hi"))), want: "hi"},
+		{name: "base64 spaces", decoder: "base64", in: []byte(" aGk=\n"), want: "hi"},
+		{name: "gzip", decoder: "gzip", in: gzipped(t, []byte("hello gzip")), want: "hello gzip"},
+		{name: "zlib", decoder: "zlib", in: zlibbed(t, []byte("hello zlib")), want: "hello zlib"},
+		{name: "snappy block", decoder: "snappy", in: snappy.Encode(nil, []byte("hello snappy")), want: "hello snappy"},
+		{name: "snappy stream", decoder: "snappy", in: snappyStream(t, []byte("hello stream")), want: "hello stream"},
+		{name: "msgpack map", decoder: "msgpack", in: packed(t, map[string]interface{}{"a": 1, "b": []string{"x"}}), want: `{"a":1,"b":["x"]}`},
+		{name: "msgpack int keys", decoder: "msgpack", in: packed(t, map[int]string{1: "one"}), want: `{"1":"one"}`},
+		{name: "msgpack string", decoder: "msgpack", in: packed(t, "text"), want: `"text"`},
+	}
+
+	d, err := NewDecoders("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := d.Pipeline([]string{tt.decoder})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := p.Decode(string(tt.in))
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Decode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuiltinDecodersInvalid(t *testing.T) {
+	tests := []struct {
+		decoder string
+		in      string
+	}{
+		{decoder: "base64", in: "not base64!"},
+		{decoder: "gzip", in: "plain"},
+		{decoder: "zlib", in: "plain"},
+		{decoder: "snappy", in: "\xff\xff\xff\xff"},
+		{decoder: "msgpack", in: ""},
+		{decoder: "msgpack", in: string(packed(t, 1)) + string(packed(t, 2))},
+	}
+
+	d, _ := NewDecoders("")
+	for _, tt := range tests {
+		t.Run(tt.decoder, func(t *testing.T) {
+			p, err := d.Pipeline([]string{tt.decoder})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, err := p.Decode(tt.in); err == nil {
+				t.Errorf("Decode(%q) = %q, want error", tt.in, got)
+			}
+		})
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	d, _ := NewDecoders("")
+	value := base64.StdEncoding.EncodeToString(gzipped(t, packed(t, []int{1, 2, 3})))
+
+	p, err := d.Pipeline([]string{"base64", "gzip", "msgpack"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := p.String(), "base64 > gzip > msgpack"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	got, err := p.Decode(value)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if want := "[1,2,3]"; got != want {
+		t.Errorf("Decode() = %q, want %q", got, want)
+	}
+
+	// The error names the failing decoder.
+	p, _ = d.Pipeline([]string{"gzip", "base64"})
+	if _, err = p.Decode(value); err == nil || !strings.HasPrefix(err.Error(), "gzip: ") {
+		t.Errorf("Decode() error = %v, want gzip error", err)
+	}
+
+	// An empty pipeline keeps the value.
+	if got, _ = Pipeline(nil).Decode(value); got != value {
+		t.Errorf("Decode() = %q, want %q", got, value)
+	}
+	if got := Pipeline(nil).String(); got != "none" {
+		t.Errorf("String() = %q, want none", got)
+	}
+}
+
+func TestPipelineUnknown(t *testing.T) {
+	d, _ := NewDecoders("")
+	for _, names := range [][]string{
+		{"rot13"},
+		{"base64", "rot13"},
+		{"protobuf:test.Missing"},
+	} {
+		if _, err := d.Pipeline(names); err == nil {
+			t.Errorf("Pipeline(%q) error = nil, want error", names)
+		}
+	}
+}
+
+func TestViewerSetDecodersUnknown(t *testing.T) {
+	d, _ := NewDecoders("")
+	v := NewViewer(context.Background(), nil, d, 0)
+	if err := v.SetDecoders([]string{"base64"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.SetDecoders([]string{"base64", "rot13"}); err == nil {
+		t.Fatal("SetDecoders() error = nil, want error")
+	}
+	// The configured pipeline is kept.
+	if got := v.pipelines[v.pipeline].String(); got != "base64" {
+		t.Errorf("pipeline = %q, want base64", got)
+	}
+}
+
+func TestDecodedSizeLimit(t *testing.T) {
+	// A value of exactly the limit is decoded, a larger one (a decompression bomb of a few KiB) is not.
+	fits := make([]byte, maxDecodedSize)
+	bomb := make([]byte, maxDecodedSize+1)
+
+	tests := []struct {
+		name    string
+		decoder string
+		encode  func(*testing.T, []byte) []byte
+	}{
+		{name: "gzip", decoder: "gzip", encode: gzipped},
+		{name: "zlib", decoder: "zlib", encode: zlibbed},
+		{name: "snappy block", decoder: "snappy", encode: func(_ *testing.T, b []byte) []byte { return snappy.Encode(nil, b) }},
+		{name: "snappy stream", decoder: "snappy", encode: snappyStream},
+	}
+
+	d, _ := NewDecoders("")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := d.Pipeline([]string{tt.decoder})
+
+			got, err := p.Decode(string(tt.encode(t, fits)))
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if len(got) != maxDecodedSize {
+				t.Errorf("len(Decode()) = %d, want %d", len(got), maxDecodedSize)
+			}
+
+			_, err = p.Decode(string(tt.encode(t, bomb)))
+			if !errors.Is(err, errDecodedSize) {
+				t.Errorf("Decode() error = %v, want %v", err, errDecodedSize)
+			}
+		})
+	}
+}
+
+func TestProtobufDecoder(t *testing.T) {
+	file := writeDescriptorSet(t)
+	d, err := NewDecoders(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The messages of the well-known types are registered, but not cycled.
+	var presets []string
+	for _, p := range d.Presets()[len(builtinDecoders):] {
+		presets = append(presets, p.String())
+	}
+	want := []string{"protobuf:test.Event", "protobuf:test.Event.Tag"}
+	if !reflect.DeepEqual(presets, want) {
+		t.Errorf("Presets() = %q, want %q", presets, want)
+	}
+
+	p, err := d.Pipeline([]string{"base64", "protobuf:test.Event"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := p.Decode(base64.StdEncoding.EncodeToString(encodeEvent(t, d)))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	var compact bytes.Buffer
+	if err = json.Compact(&compact, []byte(got)); err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"name":"login","at":"2022-01-02T03:04:05Z","tags":[{"key":"ip"}]}`; compact.String() != want {
+		t.Errorf("Decode() = %s, want %s", compact.String(), want)
+	}
+
+	p, _ = d.Pipeline([]string{"protobuf:test.Event"})
+	if _, err = p.Decode("\xff\xff"); err == nil {
+		t.Error("Decode() error = nil, want error")
+	}
+}
+
+func TestNewDecodersErrors(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.pb")
+	if err := ioutil.WriteFile(invalid, []byte("\xff\xff"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{filepath.Join(dir, "missing.pb"), invalid} {
+		if _, err := NewDecoders(file); err == nil {
+			t.Errorf("NewDecoders(%q) error = nil, want error", file)
+		}
+	}
+}
+
+// writeDescriptorSet writes a descriptor set with a test.Event message, which has a nested message and
+// imports google.protobuf.Timestamp.
+func writeDescriptorSet(t *testing.T) string {
+	t.Helper()
+
+	event := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("event.proto"),
+		Package:    proto.String("test"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/timestamp.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Event"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("at", 2, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Timestamp"),
+				field("tags", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".test.Event.Tag"),
+			},
+			NestedType: []*descriptorpb.DescriptorProto{{
+				Name:  proto.String("Tag"),
+				Field: []*descriptorpb.FieldDescriptorProto{field("key", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, "")},
+			}},
+		}},
+	}
+	event.MessageType[0].Field[2].Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+		protodesc.ToFileDescriptorProto(timestamppb.File_google_protobuf_timestamp_proto),
+		event,
+	}}
+	b, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "set.pb")
+	if err = ioutil.WriteFile(file, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func field(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+	f := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		JsonName: proto.String(name),
+		Number:   proto.Int32(number),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:     typ.Enum(),
+	}
+	if typeName != "" {
+		f.TypeName = proto.String(typeName)
+	}
+	return f
+}
+
+// encodeEvent returns an encoded test.Event message.
+func encodeEvent(t *testing.T, d *Decoders) []byte {
+	t.Helper()
+
+	mt, err := d.types.FindMessageByName("test.Event")
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := mt.Descriptor()
+	m := dynamicpb.NewMessage(md)
+	m.Set(md.Fields().ByName("name"), protoreflect.ValueOfString("login"))
+
+	at := dynamicpb.NewMessage(md.Fields().ByName("at").Message())
+	b, _ := proto.Marshal(&timestamppb.Timestamp{Seconds: 1641092645})
+	if err = proto.Unmarshal(b, at); err != nil {
+		t.Fatal(err)
+	}
+	m.Set(md.Fields().ByName("at"), protoreflect.ValueOfMessage(at))
+
+	tags := m.Mutable(md.Fields().ByName("tags")).List()
+	tag := tags.NewElement()
+	tag.Message().Set(tag.Message().Descriptor().Fields().ByName("key"), protoreflect.ValueOfString("ip"))
+	tags.Append(tag)
+
+	b, err = proto.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func gzipped(t *testing.T, b []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zlibbed(t *testing.T, b []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func snappyStream(t *testing.T, b []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := snappy.NewBufferedWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func packed(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	b, err := msgpack.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -2,57 +2,291 @@ package scanner
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strconv"
 
 	"github.com/go-redis/redis/v8"
 	r "github.com/milonoir/rv/redis"
 )
 
+const (
+	// pageSize is the number of elements fetched at once: the size of the list windows, the COUNT
+	// hint of the scan commands and the number of stream entries.
+	pageSize = 500
+	// maxDumpMemory is the largest MEMORY USAGE of the keys whose serialized size is fetched by DUMP,
+	// which transfers the whole serialized value.
+	maxDumpMemory = 1 << 20
+)
+
+// Field is a field of a Redis hash with its value.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// commander executes commands right away, like clients, or queues them, like pipelines.
+type commander interface {
+	redis.Cmdable
+	Do(ctx context.Context, args ...interface{}) *redis.Cmd
+}
+
+// Inspection is the first page of the value of a Redis key with its metadata.
+type Inspection struct {
+	Data     interface{}
+	Cursor   string
+	Metadata Metadata
+}
+
 // executor executes read-only Redis commands.
+// In cluster mode commands are routed to the node owning the key's hash slot by the client.
 type executor struct {
-	rc *redis.Client
+	rc redis.UniversalClient
 }
 
 // newExecutor returns a fully configured executor.
-func newExecutor(rc *redis.Client) *executor {
+func newExecutor(rc redis.UniversalClient) *executor {
 	return &executor{
 		rc: rc,
 	}
 }
 
 // Execute implements the Executor interface.
-func (e *executor) Execute(ctx context.Context, key string, rt r.DataType) (interface{}, error) {
+func (e *executor) Execute(ctx context.Context, key string, rt r.DataType, cursor string, reverse bool) (interface{}, string, error) {
+	return queuePage(ctx, e.rc, key, rt, cursor, reverse)()
+}
+
+// Inspect implements the Executor interface.
+func (e *executor) Inspect(ctx context.Context, key string, rt r.DataType, reverse, dump bool) (*Inspection, error) {
+	var (
+		page     func() (interface{}, string, error)
+		metadata func() Metadata
+	)
+	_, err := e.rc.Pipelined(ctx, func(p redis.Pipeliner) error {
+		page = queuePage(ctx, p, key, rt, "", reverse)
+		metadata = queueMetadata(ctx, p, key, rt, true)
+		return nil
+	})
+	// Errors of the metadata commands only make the corresponding values unknown.
+	var rerr redis.Error
+	if err != nil && !errors.As(err, &rerr) {
+		return nil, err
+	}
+
+	data, next, err := page()
+	if err != nil {
+		return nil, err
+	}
+
+	md := metadata()
+	if dump && md.Memory >= 0 && md.Memory <= maxDumpMemory {
+		if v, err := e.rc.Dump(ctx, key).Result(); err == nil {
+			md.Serialized = int64(len(v))
+		}
+	}
+	return &Inspection{Data: data, Cursor: next, Metadata: md}, nil
+}
+
+// Length implements the Executor interface.
+func (e *executor) Length(ctx context.Context, key string, rt r.DataType) (int64, error) {
+	switch rt {
+	case r.TypeList:
+		return e.rc.LLen(ctx, key).Result()
+	case r.TypeSet:
+		return e.rc.SCard(ctx, key).Result()
+	case r.TypeSortedSet:
+		return e.rc.ZCard(ctx, key).Result()
+	case r.TypeHash:
+		return e.rc.HLen(ctx, key).Result()
+	case r.TypeStream:
+		return e.rc.XLen(ctx, key).Result()
+	default:
+		return e.rc.StrLen(ctx, key).Result()
+	}
+}
+
+// queuePage queues the command fetching the page of the value at the cursor, and returns the function
+// which returns the page once the command has been executed. Clients execute the command right away.
+// Lists are paged by index, sets, sorted sets and hashes by the cursor of their scan command, and
+// streams by the ID of the first entry of the page.
+func queuePage(ctx context.Context, c commander, key string, rt r.DataType, cursor string, reverse bool) func() (interface{}, string, error) {
+	var n uint64
+	if rt != r.TypeStream && cursor != "" {
+		var err error
+		if n, err = strconv.ParseUint(cursor, 10, 64); err != nil {
+			return func() (interface{}, string, error) {
+				return nil, "", fmt.Errorf("invalid cursor: %s", cursor)
+			}
+		}
+	}
+
 	switch rt {
 	case r.TypeList:
-		return e.getList(ctx, key)
+		start := int64(n)
+		cmd := c.LRange(ctx, key, start, start+pageSize-1)
+		return func() (interface{}, string, error) {
+			items, err := cmd.Result()
+			if err != nil || len(items) < pageSize {
+				return items, "", err
+			}
+			return items, formatCursor(n + pageSize), nil
+		}
 	case r.TypeSet:
-		return e.getSet(ctx, key)
+		cmd := c.SScan(ctx, key, n, "", pageSize)
+		return func() (interface{}, string, error) {
+			members, next, err := cmd.Result()
+			return members, formatCursor(next), err
+		}
 	case r.TypeSortedSet:
-		return e.getSortedSet(ctx, key)
+		cmd := c.ZScan(ctx, key, n, "", pageSize)
+		return func() (interface{}, string, error) {
+			return parseSortedSet(cmd.Result())
+		}
 	case r.TypeHash:
-		return e.getHash(ctx, key)
+		cmd := c.HScan(ctx, key, n, "", pageSize)
+		return func() (interface{}, string, error) {
+			return parseHash(cmd.Result())
+		}
+	case r.TypeStream:
+		return queueStreamPage(ctx, c, key, cursor, reverse)
 	default:
 		// Assuming everything else is a single key.
-		return e.getKey(ctx, key)
+		cmd := c.Get(ctx, key)
+		return func() (interface{}, string, error) {
+			v, err := cmd.Result()
+			return []string{v}, "", err
+		}
 	}
 }
 
-func (e *executor) getKey(ctx context.Context, key string) ([]string, error) {
-	v, err := e.rc.Get(ctx, key).Result()
-	return []string{v}, err
+// formatCursor returns the page cursor of the scan cursor, which is empty after the last page.
+func formatCursor(next uint64) string {
+	if next == 0 {
+		return ""
+	}
+	return strconv.FormatUint(next, 10)
+}
+
+// parseSortedSet parses the member-score pairs of ZSCAN.
+func parseSortedSet(pairs []string, next uint64, err error) ([]redis.Z, string, error) {
+	if err != nil {
+		return nil, "", err
+	}
+
+	zs := make([]redis.Z, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		score, err := strconv.ParseFloat(pairs[i+1], 64)
+		if err != nil {
+			return nil, "", fmt.Errorf("parse score of %q: %w", pairs[i], err)
+		}
+		zs = append(zs, redis.Z{Member: pairs[i], Score: score})
+	}
+	return zs, formatCursor(next), nil
+}
+
+// parseHash parses the field-value pairs of HSCAN.
+func parseHash(pairs []string, next uint64, err error) ([]Field, string, error) {
+	if err != nil {
+		return nil, "", err
+	}
+
+	fields := make([]Field, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		fields = append(fields, Field{Name: pairs[i], Value: pairs[i+1]})
+	}
+	return fields, formatCursor(next), nil
 }
 
-func (e *executor) getList(ctx context.Context, key string) ([]string, error) {
-	return e.rc.LRange(ctx, key, 0, -1).Result()
+// Type implements the Executor interface.
+func (e *executor) Type(ctx context.Context, key string) (r.DataType, error) {
+	t, err := e.rc.Type(ctx, key).Result()
+	return r.ParseRedisType(t), err
 }
 
-func (e *executor) getSet(ctx context.Context, key string) ([]string, error) {
-	return e.rc.SMembers(ctx, key).Result()
+// Metadata implements the Executor interface.
+func (e *executor) Metadata(ctx context.Context, keys []string, types []r.DataType) ([]Metadata, error) {
+	results := make([]func() Metadata, len(keys))
+	_, err := e.rc.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, key := range keys {
+			results[i] = queueMetadata(ctx, p, key, types[i], false)
+		}
+		return nil
+	})
+	// Errors of single commands (e.g. OBJECT IDLETIME with an LFU eviction policy) only make the
+	// corresponding values unknown.
+	var rerr redis.Error
+	if err != nil && !errors.As(err, &rerr) {
+		return nil, err
+	}
+
+	md := make([]Metadata, len(keys))
+	for i, result := range results {
+		md[i] = result()
+	}
+	return md, nil
 }
 
-func (e *executor) getSortedSet(ctx context.Context, key string) ([]redis.Z, error) {
-	return e.rc.ZRangeWithScores(ctx, key, 0, -1).Result()
+// queueMetadata queues the commands of the metadata of the key on the pipeline, and returns the function
+// which returns the metadata once the pipeline has been executed. The access frequency is only fetched
+// with details. The serialized size is left unknown.
+func queueMetadata(ctx context.Context, p redis.Pipeliner, key string, rt r.DataType, details bool) func() Metadata {
+	var (
+		ttl      = p.PTTL(ctx, key)
+		memory   = p.MemoryUsage(ctx, key)
+		length   = lengthCmd(ctx, p, key, rt)
+		encoding = p.ObjectEncoding(ctx, key)
+		idle     = p.ObjectIdleTime(ctx, key)
+		freq     *redis.Cmd
+	)
+	if details {
+		freq = p.Do(ctx, "OBJECT", "FREQ", key)
+	}
+
+	return func() Metadata {
+		md := unknownMetadata()
+		if v, err := ttl.Result(); err == nil {
+			md.TTL = v
+		}
+		if v, err := memory.Result(); err == nil {
+			md.Memory = v
+		}
+		if length != nil {
+			if v, err := length.Result(); err == nil {
+				md.Length = v
+			}
+		}
+		if v, err := encoding.Result(); err == nil {
+			md.Encoding = v
+		}
+		if v, err := idle.Result(); err == nil {
+			md.Idle = v
+		}
+		if freq != nil {
+			if v, err := freq.Int64(); err == nil {
+				md.Freq = v
+			}
+		}
+		return md
+	}
 }
 
-func (e *executor) getHash(ctx context.Context, key string) (map[string]string, error) {
-	return e.rc.HGetAll(ctx, key).Result()
+// lengthCmd queues the Redis command returning the length of the key based on its data type.
+// It returns nil if the data type is unknown.
+func lengthCmd(ctx context.Context, p redis.Pipeliner, key string, rt r.DataType) *redis.IntCmd {
+	switch rt {
+	case r.TypeKey:
+		return p.StrLen(ctx, key)
+	case r.TypeList:
+		return p.LLen(ctx, key)
+	case r.TypeSet:
+		return p.SCard(ctx, key)
+	case r.TypeSortedSet:
+		return p.ZCard(ctx, key)
+	case r.TypeHash:
+		return p.HLen(ctx, key)
+	case r.TypeStream:
+		return p.XLen(ctx, key)
+	default:
+		return nil
+	}
 }
@@ -25,6 +25,46 @@ type Worker interface {
 	// State returns the last response and execution time of the Redis scan command and whether the worker is enabled.
 	State() ([]string, time.Time, bool)
 
+	// History returns the key counts of the recent scans in chronological order.
+	History() []Sample
+
+	// Diff returns the keys added and removed between the last two scans.
+	Diff() ([]string, []string)
+
+	// Progress returns the progress of the pass in progress in incremental mode.
+	// It returns false if there is no pass in progress.
+	Progress() (Progress, bool)
+
+	// Alerting returns true if any of the configured alert rules is firing.
+	Alerting() bool
+
+	// Truncated returns true if the last scan stopped at the configured maximum number of keys.
+	Truncated() bool
+
+	// Types returns the detected type of each matching key from the last scan.
+	// It returns nil unless the worker is configured to detect the key types.
+	Types() map[string]r.DataType
+
+	// Nodes returns the number of matching keys per cluster node from the last scan.
+	// It returns an empty map when not connected to a Redis Cluster.
+	Nodes() map[string]int
+
+	// Settings returns a copy of the configuration of the worker.
+	Settings() Config
+
+	// Trigger executes the Redis scan command immediately, even if the worker is disabled.
+	Trigger()
+
+	// Interval returns the interval of the Redis scan command.
+	Interval() time.Duration
+
+	// SetInterval changes the interval of the Redis scan command and restarts the interval.
+	SetInterval(time.Duration)
+
+	// Configure replaces the configuration of the worker, keeping its replies, history and alert state,
+	// and restarts the interval. The pattern and the type must not change.
+	Configure(Config)
+
 	// Enable enables the worker.
 	Enable()
 
@@ -35,10 +75,72 @@ type Worker interface {
 	ErrCh() <-chan string
 }
 
+// Sample is the number of matching keys found by a scan.
+type Sample struct {
+	Time  time.Time
+	Count int
+}
+
+// Progress is the progress of an incremental pass over the keyspace.
+type Progress struct {
+	// Keys is the number of matching keys found so far.
+	Keys int
+	// Node is the index of the node being scanned, starting from 1.
+	Node int
+	// Nodes is the number of nodes to be scanned.
+	Nodes int
+}
+
+// OneShot provides an interface to interact with one-shot scans.
+// A one-shot scan executes a single pass of the Redis scan command with a pattern in the background.
+type OneShot interface {
+	common.Messenger
+
+	// Pattern returns the pattern of the Redis scan command and the type of the matching keys.
+	Pattern() (string, r.DataType)
+
+	// Progress returns the progress of the scan.
+	Progress() Progress
+
+	// Done returns true if the scan is complete or has been cancelled.
+	Done() bool
+
+	// Result returns the matching keys, their type and their detected types in auto type mode.
+	// It is only complete once the scan is done.
+	Result() ([]string, r.DataType, map[string]r.DataType)
+
+	// Cancel aborts the scan.
+	Cancel()
+}
+
 // Executor provides an interface with the Redis command executor.
 type Executor interface {
-	// Execute executes a Redis read-only command based on the data type.
-	Execute(context.Context, string, r.DataType) (interface{}, error)
+	// Execute executes a Redis read-only command based on the data type, and returns a page of the
+	// elements starting at the cursor and the cursor of the next page. The cursor of the first page is
+	// empty, as is the next cursor after the last page. The cursor of lists is the index of the first
+	// item of the page, the cursor of streams is the ID of the first entry. Streams are read from the
+	// newest entry if reverse is true.
+	Execute(context.Context, string, r.DataType, string, bool) (interface{}, string, error)
+
+	// Length returns the number of elements of the Redis key based on its data type, or the length of
+	// the value of single keys.
+	Length(context.Context, string, r.DataType) (int64, error)
+
+	// Type returns the data type of the Redis key.
+	Type(context.Context, string) (r.DataType, error)
+
+	// Metadata returns the metadata of the Redis keys of the data types in a single pipeline.
+	Metadata(context.Context, []string, []r.DataType) ([]Metadata, error)
+
+	// Inspect returns the first page of the Redis key of the data type with its metadata, including the
+	// access frequency, in a single pipeline. The serialized size is only fetched by DUMP if dump is true
+	// and the memory usage of the key is known and at most 1 MiB. Streams are read from the newest entry
+	// if reverse is true.
+	Inspect(context.Context, string, r.DataType, bool, bool) (*Inspection, error)
+
+	// StreamInfo returns the details of the Redis stream: its consumer groups, their consumers and the
+	// oldest entries of their pending entries lists.
+	StreamInfo(context.Context, string) (*StreamInfo, error)
 }
 
 // Scanner provides an interface to interact with the scanner widget.
@@ -47,14 +149,63 @@ type Scanner interface {
 	common.Messenger
 	common.Scrollable
 
-	// Select returns data from the selected worker.
-	Select() ([]string, r.DataType)
+	// Select returns data from the selected worker: the matching keys, their configured type and
+	// their detected types if the worker detects them.
+	Select() ([]string, r.DataType, map[string]r.DataType)
+
+	// SelectAdded is like Select but returns the keys added since the previous scan only.
+	SelectAdded() ([]string, r.DataType, map[string]r.DataType)
+
+	// SelectRemoved is like Select but returns the keys removed since the previous scan only.
+	SelectRemoved() ([]string, r.DataType, map[string]r.DataType)
 
 	// Enable enables the selected worker.
 	Enable()
 
 	// Disable disables the selected worker.
 	Disable()
+
+	// EnableAll enables all workers.
+	EnableAll()
+
+	// DisableAll disables all workers.
+	DisableAll()
+
+	// ScanNow triggers an immediate scan of the selected worker.
+	ScanNow()
+
+	// Interval returns the scan interval of the selected worker.
+	Interval() time.Duration
+
+	// SetInterval changes the scan interval of the selected worker.
+	SetInterval(time.Duration)
+
+	// SelectHistory returns the name and the key count history of the selected worker.
+	SelectHistory() (string, []Sample)
+
+	// SelectConfig returns the name and a copy of the configuration of the selected worker.
+	SelectConfig() (string, *Config)
+
+	// AddWorker starts a new worker with the configuration. It replaces the worker with the same name,
+	// unless the pattern and the type are unchanged: the existing worker is reconfigured then.
+	AddWorker(string, *Config)
+
+	// RemoveWorker stops and removes the named worker.
+	RemoveWorker(string)
+
+	// ToggleNodes toggles between showing the patterns and the per-node key counts of the workers.
+	ToggleNodes()
+
+	// SetSortMode changes the order of the workers by name.
+	SetSortMode(common.SortMode)
+}
+
+// Chart provides an interface to interact with the key count history chart widget.
+type Chart interface {
+	common.Widget
+
+	// SetHistory sets the name of the worker and its key count history to be charted.
+	SetHistory(string, []Sample)
 }
 
 // Selector provides an interface to interact with the selector widget.
@@ -63,10 +214,78 @@ type Selector interface {
 	common.Scrollable
 
 	// Select returns the selected Redis key and data type from the list.
+	// It returns an empty key if no item matches the filter.
 	Select() (string, r.DataType)
 
-	// SetItems sets the list rows and Redis data type.
-	SetItems([]string, r.DataType)
+	// SetItems sets the list rows and Redis data type. Per-item types override the data type of the
+	// matching items. The filter is cleared.
+	SetItems([]string, r.DataType, map[string]r.DataType)
+
+	// Filter shows the items matching the query in the search mode only, with the matches highlighted.
+	// An empty query shows every item. The selection is kept on the selected item if it matches.
+	Filter(string, SearchMode) error
+
+	// ClearFilter shows every item without highlights and keeps the selection on the selected item.
+	ClearFilter()
+
+	// ShowAll shows every item with the matches of the filter still highlighted, and keeps the
+	// selection on the selected item.
+	ShowAll()
+
+	// Filtered returns true if only the items matching the filter are shown.
+	Filtered() bool
+
+	// Highlighted returns true if the matches of a filter are highlighted.
+	Highlighted() bool
+
+	// NextMatch selects the next item matching the filter in the whole list, wrapping around at the
+	// end of the list. Every item is shown with the matches highlighted.
+	NextMatch()
+
+	// PrevMatch selects the previous item matching the filter in the whole list, wrapping around at
+	// the top of the list. Every item is shown with the matches highlighted.
+	PrevMatch()
+
+	// ToggleColumns shows or hides the key metadata columns. The metadata is fetched for the visible
+	// rows only.
+	ToggleColumns()
+
+	// NextSort sorts the items by the next column. When sorting by a metadata column, the metadata of
+	// every item is fetched in the background and the items are sorted again as it arrives.
+	NextSort()
+
+	// ReverseSort reverses the sort order.
+	ReverseSort()
+
+	// SetSortMode changes the order of the keys by name.
+	SetSortMode(common.SortMode)
+}
+
+// Tree provides an interface to interact with the keyspace tree widget.
+// The tree groups Redis keys into collapsible namespaces.
+type Tree interface {
+	common.Widget
+	common.Scrollable
+
+	// Select returns the selected Redis key and data type from the tree.
+	// It returns false if the selected row is a namespace.
+	Select() (string, r.DataType, bool)
+
+	// SetItems sets the Redis keys and data type. Per-item types override the data type of the
+	// matching items.
+	SetItems([]string, r.DataType, map[string]r.DataType)
+
+	// Toggle expands or collapses the selected namespace.
+	Toggle()
+
+	// Expand expands the selected namespace.
+	Expand()
+
+	// Collapse collapses the selected namespace, or the namespace of the selected key.
+	Collapse()
+
+	// SetSortMode changes the order of the namespaces and keys by name.
+	SetSortMode(common.SortMode)
 }
 
 // Viewer provides an interface to interact with the viewer widget.
@@ -77,4 +296,36 @@ type Viewer interface {
 
 	// View shows the details of the provided Redis key based on its data type.
 	View(context.Context, string, r.DataType)
+
+	// Refresh fetches the value and the metadata of the viewed key again, and flags the elements added,
+	// modified and removed since the previous fetch.
+	Refresh(context.Context)
+
+	// ToggleOrder switches between showing streams from the oldest and from the newest entry.
+	ToggleOrder(context.Context)
+
+	// ToggleWatch starts or pauses refreshing the viewed key every interval in the background.
+	ToggleWatch()
+
+	// Interval returns the watch interval.
+	Interval() time.Duration
+
+	// SetInterval changes the watch interval.
+	SetInterval(time.Duration)
+
+	// TogglePretty switches between pretty-printed and raw JSON values.
+	TogglePretty()
+
+	// ToggleHex switches between hex dumps and escaped strings of binary values.
+	ToggleHex()
+
+	// ToggleFold folds or unfolds the JSON object or array at the selected row.
+	ToggleFold()
+
+	// SetDecoders sets the pipeline of the named decoders applied to the values of the viewed keys.
+	SetDecoders([]string) error
+
+	// NextDecoder applies the next decoder pipeline in the cycle: the pipeline set by SetDecoders,
+	// no decoding, then every single decoder.
+	NextDecoder()
 }
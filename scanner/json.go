@@ -0,0 +1,160 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jsonKind is the kind of a JSON value.
+type jsonKind int
+
+const (
+	jsonObject jsonKind = iota
+	jsonArray
+	jsonString
+	jsonNumber
+	jsonLiteral
+)
+
+// jsonNode is a JSON value. Objects keep the order of their keys.
+type jsonNode struct {
+	kind     jsonKind
+	key      string
+	hasKey   bool
+	value    string
+	children []*jsonNode
+	folded   bool
+}
+
+// parseJSON returns the parsed JSON object or array, or nil if the value is not a JSON object or array.
+func parseJSON(value string) *jsonNode {
+	v := strings.TrimSpace(value)
+	if v == "" || (v[0] != '{' && v[0] != '[') || !json.Valid([]byte(v)) {
+		return nil
+	}
+
+	dec := json.NewDecoder(strings.NewReader(v))
+	dec.UseNumber()
+	n, err := decodeJSON(dec)
+	if err != nil {
+		return nil
+	}
+	return n
+}
+
+// decodeJSON decodes the next JSON value from the decoder.
+func decodeJSON(dec *json.Decoder) (*jsonNode, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := t.(type) {
+	case json.Delim:
+		n := &jsonNode{kind: jsonObject}
+		if t == '[' {
+			n.kind = jsonArray
+		}
+		for dec.More() {
+			var key string
+			if n.kind == jsonObject {
+				k, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key = k.(string)
+			}
+			c, err := decodeJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			c.key, c.hasKey = key, n.kind == jsonObject
+			n.children = append(n.children, c)
+		}
+		// Closing delimiter.
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return n, nil
+	case string:
+		return &jsonNode{kind: jsonString, value: quoteJSON(t)}, nil
+	case json.Number:
+		return &jsonNode{kind: jsonNumber, value: t.String()}, nil
+	case nil:
+		return &jsonNode{kind: jsonLiteral, value: "null"}, nil
+	default:
+		return &jsonNode{kind: jsonLiteral, value: fmt.Sprint(t)}, nil
+	}
+}
+
+// quoteJSON returns the JSON string literal of s.
+func quoteJSON(s string) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// render appends the indented and colored lines of the node. refs holds the node of each line
+// which can be folded, or nil.
+func (n *jsonNode) render(depth int, last bool, lines *[]string, refs *[]*jsonNode) {
+	indent := strings.Repeat("  ", depth)
+	key := ""
+	if n.hasKey {
+		key = color(quoteJSON(n.key), "cyan") + ": "
+	}
+	comma := ","
+	if last {
+		comma = ""
+	}
+
+	open, closing, unit := "{", "}", "keys"
+	if n.kind == jsonArray {
+		open, closing, unit = "[", "]", "items"
+	}
+
+	switch {
+	case n.kind != jsonObject && n.kind != jsonArray:
+		*lines = append(*lines, indent+key+n.colored()+comma)
+		*refs = append(*refs, nil)
+	case len(n.children) == 0:
+		*lines = append(*lines, indent+key+open+closing+comma)
+		*refs = append(*refs, nil)
+	case n.folded:
+		*lines = append(*lines, fmt.Sprintf("%s%s%s…%s [%d %s](fg:yellow)%s", indent, key, open, closing, len(n.children), unit, comma))
+		*refs = append(*refs, n)
+	default:
+		// A trailing bracket would be taken for the start of a style by termui.
+		*lines = append(*lines, indent+key+open+" ")
+		*refs = append(*refs, n)
+		for i, c := range n.children {
+			c.render(depth+1, i == len(n.children)-1, lines, refs)
+		}
+		*lines = append(*lines, indent+closing+comma)
+		*refs = append(*refs, nil)
+	}
+}
+
+// colored returns the scalar value with its color markup.
+func (n *jsonNode) colored() string {
+	switch n.kind {
+	case jsonString:
+		return color(n.value, "green")
+	case jsonNumber:
+		return color(n.value, "yellow")
+	default:
+		return color(n.value, "magenta")
+	}
+}
+
+// color returns the text with the color markup of termui. Texts containing brackets are not colored,
+// as they would break the markup.
+func color(text, fg string) string {
+	if strings.ContainsAny(text, "[]") {
+		return text
+	}
+	return fmt.Sprintf("[%s](fg:%s)", text, fg)
+}
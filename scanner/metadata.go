@@ -0,0 +1,163 @@
+package scanner
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Column is a column of the selector.
+type Column int
+
+// Columns of the selector.
+const (
+	ColumnKey Column = iota
+	ColumnType
+	ColumnTTL
+	ColumnMemory
+	ColumnLength
+	ColumnEncoding
+	ColumnIdle
+)
+
+// columnNames are the names of the columns, indexed by Column.
+var columnNames = []string{"key", "type", "TTL", "memory", "length", "encoding", "idle"}
+
+// String implements the fmt.Stringer interface.
+func (c Column) String() string {
+	return columnNames[c]
+}
+
+// Next returns the next column in the cycle.
+func (c Column) Next() Column {
+	return (c + 1) % Column(len(columnNames))
+}
+
+// IsMetadata returns true if the column shows key metadata.
+func (c Column) IsMetadata() bool {
+	return c >= ColumnTTL
+}
+
+// Metadata is the metadata of a Redis key. Values which could not be fetched are negative, or empty in
+// case of the encoding. TTL is -1 if the key does not expire.
+type Metadata struct {
+	TTL      time.Duration
+	Memory   int64
+	Length   int64
+	Encoding string
+	Idle     time.Duration
+	// Freq is the logarithmic access frequency counter with an LFU eviction policy. Serialized is the
+	// size of the value serialized by DUMP.
+	Freq       int64
+	Serialized int64
+}
+
+const (
+	// ttlUnknown is the TTL of the keys which have no TTL information.
+	ttlUnknown = -3
+	// ttlNone is the TTL of the keys without expiry, as returned by the Redis client.
+	ttlNone = -1
+)
+
+// unknownMetadata returns metadata with every value unknown.
+func unknownMetadata() Metadata {
+	return Metadata{TTL: ttlUnknown, Memory: -1, Length: -1, Idle: -1, Freq: -1, Serialized: -1}
+}
+
+// known returns true if the value of the column is known.
+func (m Metadata) known(c Column) bool {
+	switch c {
+	case ColumnTTL:
+		return m.TTL >= 0 || m.TTL == ttlNone
+	case ColumnMemory:
+		return m.Memory >= 0
+	case ColumnLength:
+		return m.Length >= 0
+	case ColumnEncoding:
+		return m.Encoding != ""
+	case ColumnIdle:
+		return m.Idle >= 0
+	}
+	return true
+}
+
+// less compares the known values of the column. Keys without expiry have the longest TTL.
+func (m Metadata) less(o Metadata, c Column) bool {
+	switch c {
+	case ColumnTTL:
+		return ttlRank(m.TTL) < ttlRank(o.TTL)
+	case ColumnMemory:
+		return m.Memory < o.Memory
+	case ColumnLength:
+		return m.Length < o.Length
+	case ColumnEncoding:
+		return m.Encoding < o.Encoding
+	case ColumnIdle:
+		return m.Idle < o.Idle
+	}
+	return false
+}
+
+// ttlRank maps the TTL to a value which orders the keys without expiry after the expiring ones.
+func ttlRank(ttl time.Duration) time.Duration {
+	if ttl == ttlNone {
+		return math.MaxInt64
+	}
+	return ttl
+}
+
+// formatTTL returns the human readable TTL.
+func formatTTL(ttl time.Duration) string {
+	switch {
+	case ttl == ttlNone:
+		return "none"
+	case ttl < 0:
+		return "-"
+	case ttl < time.Second:
+		return ttl.String()
+	default:
+		return ttl.Truncate(time.Second).String()
+	}
+}
+
+// formatBytes returns the human readable size.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < 0 {
+		return "-"
+	}
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// formatCount returns the count, or a dash if it is unknown.
+func formatCount(n int64) string {
+	if n < 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+// formatIdle returns the human readable idle time.
+func formatIdle(d time.Duration) string {
+	if d < 0 {
+		return "-"
+	}
+	return d.String()
+}
+
+// formatEncoding returns the encoding, or a dash if it is unknown.
+func formatEncoding(enc string) string {
+	if enc == "" {
+		return "-"
+	}
+	return strings.ToLower(enc)
+}
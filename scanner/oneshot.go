@@ -0,0 +1,91 @@
+package scanner
+
+import (
+	"context"
+
+	"github.com/go-redis/redis/v8"
+	r "github.com/milonoir/rv/redis"
+)
+
+const (
+	// oneShotCount is the COUNT hint of the Redis scan command of one-shot scans.
+	oneShotCount = 1000
+)
+
+// oneShot implements the OneShot interface.
+type oneShot struct {
+	w      *worker
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewOneShot starts scanning the pattern in the background and returns the one-shot scan.
+func NewOneShot(ctx context.Context, rc redis.UniversalClient, pattern string, rt r.DataType) OneShot {
+	ctx, cancel := context.WithCancel(ctx)
+	cfg := &Config{
+		Pattern: pattern,
+		Type:    rt,
+		Count:   oneShotCount,
+		// A single iteration per run, so that the progress is updated after every page.
+		Incremental: true,
+		Iterations:  1,
+	}
+
+	o := &oneShot{
+		w:      newWorker(ctx, rc, "one-shot", cfg).(*worker),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go o.run()
+	return o
+}
+
+// run executes a single pass over the keyspace.
+func (o *oneShot) run() {
+	defer close(o.done)
+	defer close(o.w.err)
+
+	for {
+		o.w.run()
+		if _, running := o.w.Progress(); !running {
+			return
+		}
+	}
+}
+
+// Pattern implements the OneShot interface.
+func (o *oneShot) Pattern() (string, r.DataType) {
+	return o.w.Pattern()
+}
+
+// Progress implements the OneShot interface.
+func (o *oneShot) Progress() Progress {
+	p, _ := o.w.Progress()
+	return p
+}
+
+// Done implements the OneShot interface.
+func (o *oneShot) Done() bool {
+	select {
+	case <-o.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Result implements the OneShot interface.
+func (o *oneShot) Result() ([]string, r.DataType, map[string]r.DataType) {
+	reply, _, _ := o.w.State()
+	return reply, o.w.Type, o.w.Types()
+}
+
+// Cancel implements the OneShot interface.
+func (o *oneShot) Cancel() {
+	o.cancel()
+}
+
+// Messages implements the common.Messenger interface.
+func (o *oneShot) Messages() <-chan string {
+	return o.w.Messages()
+}
@@ -5,76 +5,72 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	ui "github.com/gizak/termui/v3"
 	"github.com/gizak/termui/v3/widgets"
 	"github.com/go-redis/redis/v8"
+	"github.com/milonoir/rv/common"
 	r "github.com/milonoir/rv/redis"
 )
 
 const (
-	countWidth = 7
-	ageWidth   = 10
+	intervalWidth = 7
+	sparkWidth    = 8
+	countWidth    = 7
+	diffWidth     = 13
+	ageWidth      = 10
 )
 
 var (
 	ageNew    = 30 * time.Second
 	ageMedium = 1 * time.Minute
+
+	sparks = []rune("▁▂▃▄▅▆▇█")
 )
 
 // scanner manages a set of workers and renders their output into a widgets.Table.
 type scanner struct {
 	*widgets.List
 
-	workers  map[string]Worker
-	order    []string
-	wg       sync.WaitGroup
-	cancel   context.CancelFunc
-	width    int
-	messages chan string
+	rc        redis.UniversalClient
+	ctx       context.Context
+	workers   map[string]Worker
+	cancels   map[string]context.CancelFunc
+	order     []string
+	wg        sync.WaitGroup
+	cancel    context.CancelFunc
+	width     int
+	profile   string
+	showNodes bool
+	sortMode  common.SortMode
+	messages  chan string
 }
 
 // NewScanner returns a fully configured scanner.
-func NewScanner(ctx context.Context, rc *redis.Client, configs map[string]*Config) *scanner {
+// If profile is not empty, it is shown in the title of the widget. Workers are ordered by name in the sort mode.
+func NewScanner(ctx context.Context, rc redis.UniversalClient, configs map[string]*Config, profile string, mode common.SortMode) *scanner {
 	ctx, cancel := context.WithCancel(ctx)
 
 	cn := len(configs)
 	s := &scanner{
+		rc:       rc,
+		ctx:      ctx,
 		order:    make([]string, 0, cn),
 		workers:  make(map[string]Worker, cn),
+		cancels:  make(map[string]context.CancelFunc, cn),
 		cancel:   cancel,
+		profile:  profile,
+		sortMode: mode,
 		messages: make(chan string, cn),
 	}
 
 	for name, cfg := range configs {
-		w := newWorker(ctx, rc, name, cfg)
-		s.workers[name] = w
-		s.wg.Add(2)
-		// Main worker goroutine.
-		go func() {
-			defer s.wg.Done()
-			w.Run()
-		}()
-		// Worker messages fan-in goroutine.
-		go func() {
-			defer s.wg.Done()
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				case m := <-w.ErrCh():
-					select {
-					case s.messages <- m:
-					default:
-					}
-				}
-			}
-		}()
-		s.order = append(s.order, name)
+		s.start(name, cfg)
 	}
-	sort.Strings(s.order)
+	s.sortMode.Sort(s.order)
 
 	s.List = widgets.NewList()
 	s.SelectedRowStyle = ui.NewStyle(ui.ColorWhite, ui.ColorBlue)
@@ -82,6 +78,60 @@ func NewScanner(ctx context.Context, rc *redis.Client, configs map[string]*Confi
 	return s
 }
 
+// start creates and starts a new worker.
+func (s *scanner) start(name string, cfg *Config) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	w := newWorker(ctx, s.rc, name, cfg)
+	s.workers[name] = w
+	s.cancels[name] = cancel
+	s.wg.Add(2)
+	// Main worker goroutine.
+	go func() {
+		defer s.wg.Done()
+		w.Run()
+	}()
+	// Worker messages fan-in goroutine.
+	go func() {
+		defer s.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case m, ok := <-w.ErrCh():
+				if !ok {
+					// Worker has returned.
+					return
+				}
+				// Blocks until the message is received, so that alerts are not dropped.
+				select {
+				case s.messages <- m:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	s.order = append(s.order, name)
+}
+
+// stop aborts the worker and removes it from the scanner.
+func (s *scanner) stop(name string) {
+	if cancel, ok := s.cancels[name]; ok {
+		cancel()
+	}
+	delete(s.cancels, name)
+	delete(s.workers, name)
+	for i, n := range s.order {
+		if n == name {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+	if s.SelectedRow >= len(s.order) && s.SelectedRow > 0 {
+		s.SelectedRow = len(s.order) - 1
+	}
+}
+
 // Resize implements the common.Widget interface.
 func (s *scanner) Resize(x1, y1, x2, y2 int) {
 	s.width = x2 - x1
@@ -100,13 +150,16 @@ func (s *scanner) Update() {
 		}
 	}
 	s.Title = fmt.Sprintf(" Scanners [%d] ", n)
+	if s.profile != "" {
+		s.Title = fmt.Sprintf(" Scanners [%d] - %s ", n, s.profile)
+	}
 	s.Rows = rows
 	ui.Render(s)
 }
 
 func (s *scanner) columnWidths() (v [2]int) {
-	width := s.width - countWidth - ageWidth - 5 // 5 = borders and separators
-	v[0] = width / 3                             // 3 = 1/3 of the remaining space
+	width := s.width - intervalWidth - sparkWidth - countWidth - diffWidth - ageWidth - 8 // 8 = borders and separators
+	v[0] = width / 3                                                                      // 3 = 1/3 of the remaining space
 	v[1] = width - v[0]
 	return
 }
@@ -115,18 +168,64 @@ func (s *scanner) renderRow(name string, w Worker, now time.Time, width [2]int)
 	reply, ut, enabled := w.State()
 
 	return fmt.Sprintf(
-		"%s %s %*s %s",
-		s.renderName(name, enabled, width[0]),
+		"%s %s %s %s %s %s %s",
+		s.renderName(name, enabled, w.Alerting(), width[0]),
 		s.renderPattern(w, width[1]),
-		countWidth, strconv.Itoa(len(reply)),
+		s.renderInterval(w.Interval()),
+		s.renderSparkline(w.History()),
+		s.renderCount(len(reply), w.Truncated()),
+		s.renderDiff(w.Diff()),
 		s.renderUpdated(ut, now),
 	)
 }
 
-func (s *scanner) renderName(name string, enabled bool, length int) string {
+func (s *scanner) renderInterval(interval time.Duration) string {
+	return fmt.Sprintf("[%*s](fg:blue)", intervalWidth, interval)
+}
+
+func (s *scanner) renderSparkline(history []Sample) string {
+	if len(history) > sparkWidth {
+		history = history[len(history)-sparkWidth:]
+	}
+	if len(history) == 0 {
+		return fmt.Sprintf("%*s", sparkWidth, "")
+	}
+
+	min, max := history[0].Count, history[0].Count
+	for _, h := range history {
+		if h.Count < min {
+			min = h.Count
+		}
+		if h.Count > max {
+			max = h.Count
+		}
+	}
+
+	line := make([]rune, len(history))
+	for i, h := range history {
+		idx := 0
+		if max > min {
+			idx = (h.Count - min) * (len(sparks) - 1) / (max - min)
+		}
+		line[i] = sparks[idx]
+	}
+	return fmt.Sprintf("[%*s](fg:cyan)", sparkWidth, string(line))
+}
+
+func (s *scanner) renderCount(count int, truncated bool) string {
+	if truncated {
+		return fmt.Sprintf("[%*s](fg:yellow)", countWidth, strconv.Itoa(count)+"+")
+	}
+	return fmt.Sprintf("%*s", countWidth, strconv.Itoa(count))
+}
+
+func (s *scanner) renderName(name string, enabled, alerting bool, length int) string {
 	if len(name) > length {
 		name = name[:length]
 	}
+	if alerting {
+		return fmt.Sprintf("[%*s](fg:white,bg:red)", -length, name)
+	}
 	if enabled {
 		return fmt.Sprintf("[%*s](fg:green)", -length, name)
 	}
@@ -134,15 +233,72 @@ func (s *scanner) renderName(name string, enabled bool, length int) string {
 }
 
 func (s *scanner) renderPattern(w Worker, length int) string {
+	if s.showNodes {
+		if nodes := w.Nodes(); len(nodes) > 0 {
+			return s.renderNodes(nodes, length)
+		}
+	}
+
+	progress := ""
+	if pr, ok := w.Progress(); ok {
+		progress = s.renderProgress(pr)
+		if len(progress) < length {
+			length -= len(progress)
+		} else {
+			progress = ""
+		}
+	}
+
 	pattern, _ := w.Pattern()
 	p := pattern
 	if len(pattern) > length {
 		p = pattern[:length]
 	}
 	if w.IsSingle() {
-		return fmt.Sprintf("[%*s](fg:cyan)", -length, p)
+		return fmt.Sprintf("[%*s](fg:cyan)", -length, p) + s.colorProgress(progress)
+	}
+	return fmt.Sprintf("%*s", -length, p) + s.colorProgress(progress)
+}
+
+func (s *scanner) renderProgress(pr Progress) string {
+	if pr.Nodes > 1 {
+		return fmt.Sprintf(" scanning: %d keys, node %d/%d", pr.Keys, pr.Node, pr.Nodes)
+	}
+	return fmt.Sprintf(" scanning: %d keys", pr.Keys)
+}
+
+func (s *scanner) colorProgress(progress string) string {
+	if progress == "" {
+		return ""
+	}
+	return fmt.Sprintf("[%s](fg:yellow)", progress)
+}
+
+func (s *scanner) renderNodes(nodes map[string]int, length int) string {
+	addrs := make([]string, 0, len(nodes))
+	for addr := range nodes {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+
+	counts := make([]string, len(addrs))
+	for i, addr := range addrs {
+		counts[i] = fmt.Sprintf("%s=%d", addr, nodes[addr])
+	}
+	n := strings.Join(counts, " ")
+	if len(n) > length {
+		n = n[:length]
 	}
-	return fmt.Sprintf("%*s", -length, p)
+	return fmt.Sprintf("[%*s](fg:magenta)", -length, n)
+}
+
+func (s *scanner) renderDiff(added, removed []string) string {
+	a, d := fmt.Sprintf("+%d", len(added)), fmt.Sprintf("-%d", len(removed))
+	pad := diffWidth - len(a) - len(d) - 1
+	if pad < 0 {
+		pad = 0
+	}
+	return fmt.Sprintf("%*s[%s](fg:green)/[%s](fg:red)", pad, "", a, d)
 }
 
 func (s *scanner) renderUpdated(updated, now time.Time) string {
@@ -162,20 +318,81 @@ func (s *scanner) renderUpdated(updated, now time.Time) string {
 }
 
 // Close implements the common.Widget interface.
-// Aborts all workers and waits for them to return.
+// Aborts all workers, waits for them to return and closes the messages channel.
 func (s *scanner) Close() {
 	s.cancel()
 	s.wg.Wait()
+	close(s.messages)
 }
 
 // Select implements the Scanner interface.
-func (s *scanner) Select() ([]string, r.DataType) {
+func (s *scanner) Select() ([]string, r.DataType, map[string]r.DataType) {
 	if _, w := s.selectWorker(); w != nil {
 		l, _, _ := w.State()
 		_, t := w.Pattern()
-		return l, t
+		return l, t, w.Types()
+	}
+	return nil, "", nil
+}
+
+// EnableAll implements the Scanner interface.
+func (s *scanner) EnableAll() {
+	for _, w := range s.workers {
+		w.Enable()
+	}
+	s.messages <- "[enabled](fg:green) all workers"
+}
+
+// DisableAll implements the Scanner interface.
+func (s *scanner) DisableAll() {
+	for _, w := range s.workers {
+		w.Disable()
+	}
+	s.messages <- "[disabled](fg:red) all workers"
+}
+
+// ScanNow implements the Scanner interface.
+func (s *scanner) ScanNow() {
+	if name, w := s.selectWorker(); w != nil {
+		w.Trigger()
+		s.messages <- fmt.Sprintf("[triggered](fg:cyan) worker %q", name)
+	}
+}
+
+// Interval implements the Scanner interface.
+func (s *scanner) Interval() time.Duration {
+	if _, w := s.selectWorker(); w != nil {
+		return w.Interval()
+	}
+	return 0
+}
+
+// SetInterval implements the Scanner interface.
+func (s *scanner) SetInterval(d time.Duration) {
+	if name, w := s.selectWorker(); w != nil {
+		w.SetInterval(d)
+		s.messages <- fmt.Sprintf("[interval](fg:blue) of worker %q set to %s", name, d)
+	}
+}
+
+// SelectAdded implements the Scanner interface.
+func (s *scanner) SelectAdded() ([]string, r.DataType, map[string]r.DataType) {
+	if _, w := s.selectWorker(); w != nil {
+		added, _ := w.Diff()
+		_, t := w.Pattern()
+		return added, t, w.Types()
+	}
+	return nil, "", nil
+}
+
+// SelectRemoved implements the Scanner interface.
+func (s *scanner) SelectRemoved() ([]string, r.DataType, map[string]r.DataType) {
+	if _, w := s.selectWorker(); w != nil {
+		_, removed := w.Diff()
+		_, t := w.Pattern()
+		return removed, t, nil
 	}
-	return nil, ""
+	return nil, "", nil
 }
 
 // Enable implements the Scanner interface.
@@ -194,7 +411,71 @@ func (s *scanner) Disable() {
 	}
 }
 
+// SelectHistory implements the Scanner interface.
+func (s *scanner) SelectHistory() (string, []Sample) {
+	if name, w := s.selectWorker(); w != nil {
+		return name, w.History()
+	}
+	return "", nil
+}
+
+// SelectConfig implements the Scanner interface.
+func (s *scanner) SelectConfig() (string, *Config) {
+	if name, w := s.selectWorker(); w != nil {
+		cfg := w.Settings()
+		return name, &cfg
+	}
+	return "", nil
+}
+
+// AddWorker implements the Scanner interface.
+// An existing worker with the same name is replaced, so that it starts over, if its pattern or type has
+// changed. Otherwise only its settings are changed, keeping its history.
+func (s *scanner) AddWorker(name string, cfg *Config) {
+	if w, ok := s.workers[name]; ok {
+		if pattern, rt := w.Pattern(); pattern == cfg.Pattern && rt == cfg.Type {
+			w.Configure(*cfg)
+			s.messages <- fmt.Sprintf("[updated](fg:green) worker %q", name)
+			return
+		}
+	}
+	s.stop(name)
+	s.start(name, cfg)
+	s.sortMode.Sort(s.order)
+	s.messages <- fmt.Sprintf("[added](fg:green) worker %q", name)
+}
+
+// SetSortMode implements the Scanner interface.
+func (s *scanner) SetSortMode(mode common.SortMode) {
+	name, _ := s.selectWorker()
+	s.sortMode = mode
+	s.sortMode.Sort(s.order)
+	for i, n := range s.order {
+		if n == name {
+			s.SelectedRow = i
+			break
+		}
+	}
+}
+
+// RemoveWorker implements the Scanner interface.
+func (s *scanner) RemoveWorker(name string) {
+	if _, ok := s.workers[name]; !ok {
+		return
+	}
+	s.stop(name)
+	s.messages <- fmt.Sprintf("[removed](fg:red) worker %q", name)
+}
+
+// ToggleNodes implements the Scanner interface.
+func (s *scanner) ToggleNodes() {
+	s.showNodes = !s.showNodes
+}
+
 func (s *scanner) selectWorker() (string, Worker) {
+	if len(s.order) == 0 {
+		return "", nil
+	}
 	name := s.order[s.List.SelectedRow]
 	if w, ok := s.workers[name]; ok {
 		return name, w
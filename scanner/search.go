@@ -0,0 +1,126 @@
+package scanner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SearchMode is the way a search query matches the items.
+type SearchMode int
+
+const (
+	// SearchSubstring matches the items containing the query, ignoring case.
+	SearchSubstring SearchMode = iota
+	// SearchGlob matches the items with a Redis style glob pattern.
+	SearchGlob
+	// SearchRegex matches the items with a regular expression.
+	SearchRegex
+)
+
+// String implements the fmt.Stringer interface.
+func (m SearchMode) String() string {
+	switch m {
+	case SearchGlob:
+		return "Glob"
+	case SearchRegex:
+		return "Regex"
+	default:
+		return "Substring"
+	}
+}
+
+// Next returns the next search mode in the cycle.
+func (m SearchMode) Next() SearchMode {
+	return (m + 1) % (SearchRegex + 1)
+}
+
+// matcher matches the items with a compiled search query.
+type matcher struct {
+	re *regexp.Regexp
+	// groups is true if the captured groups are the parts of the match to highlight.
+	groups bool
+}
+
+// newMatcher compiles the query in the search mode.
+func newMatcher(query string, mode SearchMode) (*matcher, error) {
+	switch mode {
+	case SearchGlob:
+		re, err := regexp.Compile(globToRegexp(query))
+		return &matcher{re: re, groups: true}, err
+	case SearchRegex:
+		re, err := regexp.Compile(query)
+		return &matcher{re: re}, err
+	default:
+		return &matcher{re: regexp.MustCompile("(?i)" + regexp.QuoteMeta(query))}, nil
+	}
+}
+
+// match returns the byte ranges of the item to highlight, or nil if the item does not match.
+func (m *matcher) match(item string) [][]int {
+	if !m.groups {
+		return m.re.FindAllStringIndex(item, -1)
+	}
+
+	loc := m.re.FindStringSubmatchIndex(item)
+	if loc == nil {
+		return nil
+	}
+	ranges := make([][]int, 0, len(loc)/2)
+	for i := 2; i < len(loc); i += 2 {
+		if loc[i] >= 0 && loc[i] < loc[i+1] {
+			ranges = append(ranges, loc[i:i+2])
+		}
+	}
+	return ranges
+}
+
+// globToRegexp converts a Redis style glob pattern to an anchored regular expression. Literal runs of
+// the pattern are captured, so that they can be highlighted.
+func globToRegexp(glob string) string {
+	var (
+		b       strings.Builder
+		literal strings.Builder
+	)
+	flush := func() {
+		if literal.Len() > 0 {
+			b.WriteString("(" + regexp.QuoteMeta(literal.String()) + ")")
+			literal.Reset()
+		}
+	}
+
+	b.WriteString("^")
+	runes := []rune(glob)
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; c {
+		case '*':
+			flush()
+			b.WriteString(".*")
+		case '?':
+			flush()
+			b.WriteString(".")
+		case '[':
+			end := i + 1
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end == len(runes) {
+				// Unterminated class, match it literally.
+				literal.WriteRune(c)
+				continue
+			}
+			flush()
+			b.WriteString("[" + string(runes[i+1:end]) + "]")
+			i = end
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+			}
+			literal.WriteRune(runes[i])
+		default:
+			literal.WriteRune(c)
+		}
+	}
+	flush()
+	b.WriteString("$")
+	return b.String()
+}
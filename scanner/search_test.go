@@ -0,0 +1,116 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob string
+		want string
+	}{
+		{glob: "", want: "^$"},
+		{glob: "user:1", want: "^(user:1)$"},
+		{glob: "user:*", want: "^(user:).*$"},
+		{glob: "*:session", want: "^.*(:session)$"},
+		{glob: "job:?", want: "^(job:).$"},
+		{glob: "job:[abc]", want: "^(job:)[abc]$"},
+		{glob: "job:[^abc]", want: "^(job:)[^abc]$"},
+		{glob: "job:[a-z]*", want: "^(job:)[a-z].*$"},
+		{glob: "job:[abc", want: `^(job:\[abc)$`},
+		{glob: `job:\*`, want: `^(job:\*)$`},
+		{glob: `job:\?\[x\]`, want: `^(job:\?\[x\])$`},
+		{glob: `trailing\`, want: `^(trailing\\)$`},
+		{glob: "a.b+c(d)|e$^{1}", want: `^(a\.b\+c\(d\)\|e\$\^\{1\})$`},
+		{glob: "ключ:*", want: "^(ключ:).*$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.glob, func(t *testing.T) {
+			if got := globToRegexp(tt.glob); got != tt.want {
+				t.Errorf("globToRegexp(%q) = %q, want %q", tt.glob, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		mode  SearchMode
+		item  string
+		want  [][]int
+	}{
+		// Substring
+		{name: "substring", query: "ess", mode: SearchSubstring, item: "session", want: [][]int{{1, 4}}},
+		{name: "substring ignores case", query: "USER", mode: SearchSubstring, item: "app:user:User", want: [][]int{{4, 8}, {9, 13}}},
+		{name: "substring is literal", query: "a.b", mode: SearchSubstring, item: "axb a.b", want: [][]int{{4, 7}}},
+		{name: "substring brackets are literal", query: "[1]", mode: SearchSubstring, item: "list[1]", want: [][]int{{4, 7}}},
+		{name: "substring no match", query: "job", mode: SearchSubstring, item: "session"},
+
+		// Glob
+		{name: "glob star", query: "user:*", mode: SearchGlob, item: "user:42", want: [][]int{{0, 5}}},
+		{name: "glob literals around star", query: "user:*:name", mode: SearchGlob, item: "user:42:name", want: [][]int{{0, 5}, {7, 12}}},
+		{name: "glob is anchored", query: "user:*", mode: SearchGlob, item: "app:user:42"},
+		{name: "glob is case sensitive", query: "user:*", mode: SearchGlob, item: "USER:42"},
+		{name: "glob question mark", query: "job:?", mode: SearchGlob, item: "job:7", want: [][]int{{0, 4}}},
+		{name: "glob question mark single character", query: "job:?", mode: SearchGlob, item: "job:77"},
+		{name: "glob class", query: "job:[ab]", mode: SearchGlob, item: "job:b", want: [][]int{{0, 4}}},
+		{name: "glob class no match", query: "job:[ab]", mode: SearchGlob, item: "job:c"},
+		{name: "glob negated class", query: "job:[^ab]", mode: SearchGlob, item: "job:c", want: [][]int{{0, 4}}},
+		{name: "glob negated class no match", query: "job:[^ab]", mode: SearchGlob, item: "job:a"},
+		{name: "glob escaped star", query: `job:\*`, mode: SearchGlob, item: "job:*", want: [][]int{{0, 5}}},
+		{name: "glob escaped star is literal", query: `job:\*`, mode: SearchGlob, item: "job:1"},
+		{name: "glob metacharacters are literal", query: "a.b*", mode: SearchGlob, item: "a.bc", want: [][]int{{0, 3}}},
+		{name: "glob dot is not a wildcard", query: "a.b*", mode: SearchGlob, item: "axbc"},
+		{name: "glob only wildcards", query: "*", mode: SearchGlob, item: "anything", want: [][]int{}},
+
+		// Regex
+		{name: "regex", query: `job:\d+`, mode: SearchRegex, item: "job:12 job:3", want: [][]int{{0, 6}, {7, 12}}},
+		{name: "regex is not anchored", query: "b+", mode: SearchRegex, item: "abbc", want: [][]int{{1, 3}}},
+		{name: "regex no match", query: `^\d+$`, mode: SearchRegex, item: "job:1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := newMatcher(tt.query, tt.mode)
+			if err != nil {
+				t.Fatalf("newMatcher(%q, %s) error = %v", tt.query, tt.mode, err)
+			}
+			if got := m.match(tt.item); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("match(%q) = %v, want %v", tt.item, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatcherErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		mode  SearchMode
+	}{
+		{query: "job:[]", mode: SearchGlob},
+		{query: "job:[z-a]", mode: SearchGlob},
+		{query: "(", mode: SearchRegex},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if _, err := newMatcher(tt.query, tt.mode); err == nil {
+				t.Errorf("newMatcher(%q, %s) error = nil, want error", tt.query, tt.mode)
+			}
+		})
+	}
+}
+
+func TestSearchModeNext(t *testing.T) {
+	want := []SearchMode{SearchGlob, SearchRegex, SearchSubstring}
+	m := SearchSubstring
+	for _, w := range want {
+		if m = m.Next(); m != w {
+			t.Fatalf("Next() = %s, want %s", m, w)
+		}
+	}
+}
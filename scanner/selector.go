@@ -1,75 +1,656 @@
 package scanner
 
 import (
+	"context"
 	"fmt"
+	"image"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	ui "github.com/gizak/termui/v3"
 	"github.com/gizak/termui/v3/widgets"
+	"github.com/go-redis/redis/v8"
+	"github.com/milonoir/rv/common"
 	r "github.com/milonoir/rv/redis"
 )
 
 const (
 	typeWidth = 6
+
+	// Widths of the metadata columns.
+	ttlWidth      = 10
+	memoryWidth   = 8
+	lengthWidth   = 9
+	encodingWidth = 10
+	idleWidth     = 10
+	metadataWidth = ttlWidth + memoryWidth + lengthWidth + encodingWidth + idleWidth + 5 // 5 = separators
+
+	// minKeyWidth is the minimum width of the key column when the metadata columns are shown.
+	minKeyWidth = 20
+
+	selectorTitle = " Select an item to inspect "
+)
+
+var (
+	metadataTimeout = 3 * time.Second
 )
 
 type selector struct {
 	*widgets.List
 
+	ctx      context.Context
+	executor Executor
+
+	// all holds every item, items holds the items matching the filter.
+	all       []string
 	items     []string
 	itemWidth int
 	rtype     r.DataType
-	mtx       sync.Mutex
+	types     map[string]r.DataType
+	rts       map[r.DataType]string
+	matcher   *matcher
+	query     string
+	// narrowed is true if only the items matching the filter are listed. Otherwise the matches are
+	// highlighted in the whole list.
+	narrowed bool
+	columns  bool
+	sortBy   Column
+	sortMode common.SortMode
+	desc     bool
+	metadata map[string]Metadata
+	mtx      sync.Mutex
+
+	// stopFetch cancels the background fetch of the metadata of every item, pending is the number of
+	// items whose metadata is still being fetched, and resort is true if the items must be sorted
+	// again with the metadata fetched since.
+	stopFetch context.CancelFunc
+	pending   int
+	resort    bool
+	// stopVisible cancels the background fetch of the metadata of the rows around the selection.
+	// waiting holds the items rendered before their metadata had been fetched.
+	stopVisible context.CancelFunc
+	waiting     map[string]bool
 }
 
-func NewSelector() *selector {
+func NewSelector(ctx context.Context, rc redis.UniversalClient, mode common.SortMode) *selector {
 	s := &selector{
-		List: widgets.NewList(),
+		List:     widgets.NewList(),
+		ctx:      ctx,
+		executor: newExecutor(rc),
+		sortMode: mode,
+		waiting:  make(map[string]bool),
 	}
-	s.Title = " Select an item to inspect "
+	s.Title = selectorTitle
 	s.SelectedRowStyle = ui.NewStyle(ui.ColorWhite, ui.ColorBlue)
 
 	return s
 }
 
+// Update implements the common.Widget interface.
+// The items are sorted again if metadata has been fetched in the background. The metadata of the rows
+// around the selection is fetched in the background if the metadata columns are shown.
 func (s *selector) Update() {
+	s.mtx.Lock()
+	if s.resort {
+		s.resort = false
+		selected := s.selected()
+		s.sort()
+		s.filter(selected)
+	}
+	if s.columns {
+		s.fetchVisible()
+	}
+	s.mtx.Unlock()
+
 	ui.Render(s)
 }
 
+// Draw implements the termui Drawable interface. The header of the columns is drawn above the rows.
+func (s *selector) Draw(buf *ui.Buffer) {
+	s.List.Draw(buf)
+	if !s.columns {
+		return
+	}
+
+	cells := ui.ParseStyles(s.renderHeader(), ui.NewStyle(ui.ColorCyan))
+	for i, c := range cells {
+		x := s.Inner.Min.X + i
+		if x >= s.Inner.Max.X {
+			break
+		}
+		buf.SetCell(c, image.Pt(x, s.Inner.Min.Y-1))
+	}
+}
+
 func (s *selector) Resize(x1, y1, x2, y2 int) {
 	s.itemWidth = x2 - x1 - typeWidth - 3 // 3 = borders + separator
 	s.SetRect(x1, y1, x2, y2)
 }
 
-func (s *selector) Close() {}
+func (s *selector) Close() {
+	s.mtx.Lock()
+	s.cancelFetch()
+	s.cancelVisible()
+	s.mtx.Unlock()
+}
 
-func (s *selector) SetItems(items []string, rtype r.DataType) {
+func (s *selector) SetItems(items []string, rtype r.DataType, types map[string]r.DataType) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
 
+	s.cancelFetch()
+	s.cancelVisible()
 	s.SelectedRow = 0
-	sort.Strings(items)
-	s.items = items
+	// The items are sorted in place, and the caller may share them, e.g. with the tree.
+	s.all = append([]string(nil), items...)
 	s.rtype = rtype
-	rt := s.renderType()
-	s.Rows = make([]string, len(items))
-	for i, item := range items {
-		s.Rows[i] = s.renderRow(item, rt)
+	s.types = types
+	s.rts = make(map[r.DataType]string)
+	s.metadata = make(map[string]Metadata)
+	s.waiting = make(map[string]bool)
+	s.matcher = nil
+	s.query = ""
+	if s.sortBy.IsMetadata() {
+		s.fetchAll()
+	}
+	s.sort()
+	s.filter("")
+}
+
+// ToggleColumns implements the Selector interface.
+func (s *selector) ToggleColumns() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.setColumns(!s.columns)
+	s.filter(s.selected())
+}
+
+// NextSort implements the Selector interface.
+func (s *selector) NextSort() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.sortBy = s.sortBy.Next()
+	if s.sortBy.IsMetadata() {
+		s.setColumns(true)
+		s.fetchAll()
+	} else {
+		s.cancelFetch()
+	}
+	selected := s.selected()
+	s.sort()
+	s.filter(selected)
+}
+
+// SetSortMode implements the Selector interface.
+func (s *selector) SetSortMode(mode common.SortMode) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.sortMode = mode
+	selected := s.selected()
+	s.sort()
+	s.filter(selected)
+}
+
+// ReverseSort implements the Selector interface.
+func (s *selector) ReverseSort() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.desc = !s.desc
+	selected := s.selected()
+	s.sort()
+	s.filter(selected)
+}
+
+// setColumns shows or hides the metadata columns. A row is reserved for the header of the columns.
+// Must be called with the lock held.
+func (s *selector) setColumns(columns bool) {
+	s.columns = columns
+	s.PaddingTop = 0
+	if columns {
+		s.PaddingTop = 1
+	}
+	s.SetRect(s.Min.X, s.Min.Y, s.Max.X, s.Max.Y)
+}
+
+// sort sorts the items by the sort column. Items are sorted by key within equal values, and
+// items with unknown metadata are sorted last. Must be called with the lock held.
+func (s *selector) sort() {
+	c := s.sortBy
+	sort.SliceStable(s.all, func(i, j int) bool {
+		a, b := s.all[i], s.all[j]
+		switch {
+		case c == ColumnType:
+			if ta, tb := s.itemType(a), s.itemType(b); ta != tb {
+				return (ta < tb) != s.desc
+			}
+		case c.IsMetadata():
+			ma, mb := s.itemMetadata(a), s.itemMetadata(b)
+			ka, kb := ma.known(c), mb.known(c)
+			if ka != kb {
+				return ka
+			}
+			if ka && ma.less(mb, c) {
+				return !s.desc
+			}
+			if kb && mb.less(ma, c) {
+				return s.desc
+			}
+		default:
+			return s.sortMode.Less(a, b) != s.desc
+		}
+		return s.sortMode.Less(a, b)
+	})
+}
+
+// fetchAll starts fetching the metadata of every item which has no metadata yet in the background,
+// replacing the fetch in progress. The items are sorted again by the next update after every batch.
+// Must be called with the lock held.
+func (s *selector) fetchAll() {
+	s.cancelFetch()
+	missing, types := s.missing(s.all)
+	if len(missing) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.stopFetch = cancel
+	s.pending = len(missing)
+	go func() {
+		defer cancel()
+		for i := 0; i < len(missing); i += typeBatchSize {
+			end := i + typeBatchSize
+			if end > len(missing) {
+				end = len(missing)
+			}
+			md := s.fetchBatch(ctx, missing[i:end], types[i:end])
+
+			s.mtx.Lock()
+			if ctx.Err() != nil {
+				// Replaced by another fetch, or the items have changed.
+				s.mtx.Unlock()
+				return
+			}
+			for j, key := range missing[i:end] {
+				s.metadata[key] = md[j]
+			}
+			s.pending -= end - i
+			s.resort = true
+			s.mtx.Unlock()
+		}
+	}()
+}
+
+// cancelFetch cancels the background fetch in progress, if any. Must be called with the lock held.
+func (s *selector) cancelFetch() {
+	if s.stopFetch != nil {
+		s.stopFetch()
+		s.stopFetch = nil
+	}
+	s.pending = 0
+}
+
+// missing returns the keys which have no metadata yet with their types. Must be called with the lock
+// held.
+func (s *selector) missing(keys []string) ([]string, []r.DataType) {
+	var (
+		missing []string
+		types   []r.DataType
+	)
+	for _, key := range keys {
+		if _, ok := s.metadata[key]; ok {
+			continue
+		}
+		missing = append(missing, key)
+		types = append(types, s.itemType(key))
+	}
+	return missing, types
+}
+
+// fetchBatch fetches the metadata of a batch of keys in a single pipeline, with a timeout of its own.
+// The metadata of the keys is unknown if the pipeline fails.
+func (s *selector) fetchBatch(ctx context.Context, keys []string, types []r.DataType) []Metadata {
+	ctx, cancel := context.WithTimeout(ctx, metadataTimeout)
+	defer cancel()
+
+	md, err := s.executor.Metadata(ctx, keys, types)
+	if err != nil {
+		md = make([]Metadata, len(keys))
+		for i := range md {
+			md[i] = unknownMetadata()
+		}
+	}
+	return md
+}
+
+// fetchVisible renders again the rows which can be visible around the selected row whose metadata has
+// been fetched since they were rendered. The metadata of the other rows is fetched in the background,
+// unless a fetch is already in progress, and they are rendered again by a later update. Must be called
+// with the lock held.
+func (s *selector) fetchVisible() {
+	h := s.Inner.Dy()
+	from, to := s.SelectedRow-h, s.SelectedRow+h+1
+	if from < 0 {
+		from = 0
+	}
+	if to > len(s.items) {
+		to = len(s.items)
+	}
+
+	var missing []string
+	for i := from; i < to; i++ {
+		item := s.items[i]
+		if _, ok := s.metadata[item]; !ok {
+			missing = append(missing, item)
+			continue
+		}
+		if s.waiting[item] {
+			delete(s.waiting, item)
+			s.Rows[i] = s.renderRow(item, s.match(item))
+		}
+	}
+	if len(missing) == 0 || s.stopVisible != nil {
+		return
+	}
+
+	keys, types := s.missing(missing)
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.stopVisible = cancel
+	go func() {
+		defer cancel()
+		md := s.fetchBatch(ctx, keys, types)
+
+		s.mtx.Lock()
+		defer s.mtx.Unlock()
+		if ctx.Err() != nil {
+			// The items have changed.
+			return
+		}
+		for i, key := range keys {
+			s.metadata[key] = md[i]
+		}
+		s.stopVisible = nil
+	}()
+}
+
+// cancelVisible cancels the background fetch of the metadata of the rows around the selection, if any.
+// Must be called with the lock held.
+func (s *selector) cancelVisible() {
+	if s.stopVisible != nil {
+		s.stopVisible()
+		s.stopVisible = nil
+	}
+}
+
+// itemMetadata returns the fetched metadata of the item. Must be called with the lock held.
+func (s *selector) itemMetadata(item string) Metadata {
+	if md, ok := s.metadata[item]; ok {
+		return md
+	}
+	return unknownMetadata()
+}
+
+// Filter implements the Selector interface.
+func (s *selector) Filter(query string, mode SearchMode) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if query == "" {
+		s.matcher = nil
+	} else {
+		m, err := newMatcher(query, mode)
+		if err != nil {
+			return err
+		}
+		s.matcher = m
+	}
+	s.query = query
+	s.narrowed = true
+	s.filter(s.selected())
+	return nil
+}
+
+// ClearFilter implements the Selector interface.
+func (s *selector) ClearFilter() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.matcher = nil
+	s.query = ""
+	s.filter(s.selected())
+}
+
+// ShowAll implements the Selector interface.
+func (s *selector) ShowAll() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.narrowed = false
+	s.filter(s.selected())
+}
+
+// Filtered implements the Selector interface.
+func (s *selector) Filtered() bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.matcher != nil && s.narrowed
+}
+
+// Highlighted implements the Selector interface.
+func (s *selector) Highlighted() bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.matcher != nil
+}
+
+// NextMatch implements the Selector interface.
+func (s *selector) NextMatch() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.jump(1)
+}
+
+// PrevMatch implements the Selector interface.
+func (s *selector) PrevMatch() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.jump(-1)
+}
+
+// jump selects the next matching item in the direction in the whole list, wrapping around. The list
+// is widened to every item first, so that the matches are shown in their context. Must be called with
+// the lock held.
+func (s *selector) jump(dir int) {
+	if s.matcher == nil {
+		return
+	}
+	if s.narrowed {
+		s.narrowed = false
+		s.filter(s.selected())
+	}
+
+	n := len(s.items)
+	for i := 1; i <= n; i++ {
+		row := ((s.SelectedRow+dir*i)%n + n) % n
+		if s.match(s.items[row]) != nil {
+			s.SelectedRow = row
+			return
+		}
+	}
+}
+
+// filter renders the items matching the filter, or every item with the matches highlighted if the
+// list is not narrowed, and keeps the selection on the item if it is listed. Must be called with the
+// lock held.
+func (s *selector) filter(selected string) {
+	s.items = s.items[:0]
+	s.Rows = s.Rows[:0]
+	row, matching := 0, 0
+	for _, item := range s.all {
+		ranges := s.match(item)
+		if ranges != nil {
+			matching++
+		}
+		if s.matcher != nil && s.narrowed && ranges == nil {
+			continue
+		}
+		if item == selected {
+			row = len(s.items)
+		}
+		s.items = append(s.items, item)
+		s.Rows = append(s.Rows, s.renderRow(item, ranges))
+	}
+	s.SelectedRow = row
+
+	s.Title = selectorTitle
+	if s.matcher != nil {
+		s.Title = fmt.Sprintf(" Select an item to inspect - %d/%d matching %q ", matching, len(s.all), s.query)
+	}
+	if s.pending > 0 {
+		s.Title += fmt.Sprintf("- loading %s of %d keys ", s.sortBy, s.pending)
+	}
+}
+
+// match returns the ranges of the item matching the filter, or nil if there is no filter or the item
+// does not match. Must be called with the lock held.
+func (s *selector) match(item string) [][]int {
+	if s.matcher == nil {
+		return nil
+	}
+	return s.matcher.match(item)
+}
+
+// selected returns the selected item, or an empty string if there are no items. Must be called with
+// the lock held.
+func (s *selector) selected() string {
+	if s.SelectedRow < 0 || s.SelectedRow >= len(s.items) {
+		return ""
+	}
+	return s.items[s.SelectedRow]
+}
+
+func (s *selector) renderRow(item string, ranges [][]int) string {
+	t := s.itemType(item)
+	rt, ok := s.rts[t]
+	if !ok {
+		rt = s.renderType(t)
+		s.rts[t] = rt
+	}
+	if !s.columns {
+		return fmt.Sprintf("%s %s", rt, renderItem(item, ranges, s.itemWidth, false))
+	}
+
+	md, ok := s.metadata[item]
+	if !ok {
+		// Rendered again once the metadata has been fetched.
+		md = unknownMetadata()
+		s.waiting[item] = true
+	}
+	return fmt.Sprintf("%s %s %*s %*s %*s %*s %*s", rt, renderItem(item, ranges, s.keyWidth(), true),
+		ttlWidth, formatTTL(md.TTL),
+		memoryWidth, formatBytes(md.Memory),
+		lengthWidth, formatCount(md.Length),
+		encodingWidth, formatEncoding(md.Encoding),
+		idleWidth, formatIdle(md.Idle),
+	)
+}
+
+// renderHeader returns the header of the columns with the sort column marked.
+func (s *selector) renderHeader() string {
+	names := make([]string, len(columnNames))
+	for i, name := range columnNames {
+		if Column(i) == s.sortBy {
+			arrow := "▲"
+			if s.desc {
+				arrow = "▼"
+			}
+			name = name + arrow
+		}
+		names[i] = name
+	}
+	return fmt.Sprintf("%*s %*s %*s %*s %*s %*s %*s",
+		-typeWidth, names[ColumnType],
+		-s.keyWidth(), names[ColumnKey],
+		ttlWidth, names[ColumnTTL],
+		memoryWidth, names[ColumnMemory],
+		lengthWidth, names[ColumnLength],
+		encodingWidth, names[ColumnEncoding],
+		idleWidth, names[ColumnIdle],
+	)
+}
+
+// keyWidth returns the width of the key column when the metadata columns are shown.
+func (s *selector) keyWidth() int {
+	if w := s.itemWidth - metadataWidth; w > minKeyWidth {
+		return w
 	}
+	return minKeyWidth
 }
 
-func (s *selector) renderRow(item, rt string) string {
-	return fmt.Sprintf("%s %*s", rt, -s.itemWidth, item)
+// renderItem returns the item padded to the width, with the ranges highlighted. The item is cut
+// at the width if truncate is true. The item is escaped, so that it is not parsed as style markup.
+func renderItem(item string, ranges [][]int, width int, truncate bool) string {
+	runes := []rune(item)
+	if truncate && len(runes) > width {
+		item = string(runes[:width-1])
+		runes = runes[:width-1]
+		// Drop the highlights beyond the cut.
+		clipped := make([][]int, 0, len(ranges))
+		for _, m := range ranges {
+			if m[0] >= len(item) {
+				break
+			}
+			end := m[1]
+			if end > len(item) {
+				end = len(item)
+			}
+			clipped = append(clipped, []int{m[0], end})
+		}
+		ranges = clipped
+		item += "…"
+		runes = append(runes, '…')
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range ranges {
+		if m[0] < last || m[0] == m[1] {
+			continue
+		}
+		b.WriteString(common.EscapeMarkup(item[last:m[0]]))
+		b.WriteString(fmt.Sprintf("[%s](fg:black,bg:yellow)", common.EscapeMarkup(item[m[0]:m[1]])))
+		last = m[1]
+	}
+	b.WriteString(common.EscapeMarkup(item[last:]))
+	if pad := width - len(runes); pad > 0 {
+		b.WriteString(strings.Repeat(" ", pad))
+	}
+	return b.String()
 }
 
-func (s *selector) renderType() string {
-	return fmt.Sprintf("[%*s](fg:green)", -typeWidth, strings.ToUpper(string(s.rtype)))
+func (s *selector) renderType(t r.DataType) string {
+	return fmt.Sprintf("[%*s](fg:green)", -typeWidth, strings.ToUpper(string(t)))
+}
+
+// itemType returns the detected type of the item if there is one, the data type of the list otherwise.
+func (s *selector) itemType(item string) r.DataType {
+	if t, ok := s.types[item]; ok {
+		return t
+	}
+	return s.rtype
 }
 
 func (s *selector) Select() (item string, rtype r.DataType) {
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
-	return s.items[s.SelectedRow], s.rtype
+	item = s.selected()
+	if item == "" {
+		return "", s.rtype
+	}
+	return item, s.itemType(item)
 }
@@ -0,0 +1,369 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// pelSize is the number of the oldest pending entries shown for each consumer group.
+	pelSize = 10
+)
+
+// StreamEntry is an entry of a Redis stream with its fields in the order they were added.
+type StreamEntry struct {
+	ID     string
+	Fields []Field
+}
+
+// StreamInfo is the state of a Redis stream. Values which are not reported by the Redis server are -1,
+// e.g. the number of entries ever added before Redis 7.
+type StreamInfo struct {
+	Length          int64
+	FirstID         string
+	LastID          string
+	LastGeneratedID string
+	RadixTreeKeys   int64
+	RadixTreeNodes  int64
+	EntriesAdded    int64
+	Groups          []StreamGroup
+}
+
+// StreamGroup is a consumer group of a Redis stream. Lag is the number of entries not delivered to the
+// group yet, -1 if it is unknown. Lower and Higher are the smallest and the greatest IDs of the pending
+// entries, and PEL holds the oldest pending entries.
+type StreamGroup struct {
+	Name            string
+	LastDeliveredID string
+	EntriesRead     int64
+	Lag             int64
+	Pending         int64
+	Lower           string
+	Higher          string
+	Consumers       []StreamConsumer
+	PEL             []redis.XPendingExt
+}
+
+// StreamConsumer is a consumer of a consumer group.
+type StreamConsumer struct {
+	Name    string
+	Pending int64
+	Idle    time.Duration
+}
+
+// StreamInfo implements the Executor interface.
+// XINFO is sent as a raw command, as its reply has gained fields across Redis versions.
+func (e *executor) StreamInfo(ctx context.Context, key string) (*StreamInfo, error) {
+	var stream, groups *redis.Cmd
+	if _, err := e.rc.Pipelined(ctx, func(p redis.Pipeliner) error {
+		stream = p.Do(ctx, "XINFO", "STREAM", key)
+		groups = p.Do(ctx, "XINFO", "GROUPS", key)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	info, err := parseStreamInfo(stream.Val())
+	if err != nil {
+		return nil, fmt.Errorf("parse stream info: %w", err)
+	}
+	if info.Groups, err = parseStreamGroups(groups.Val(), info.LastGeneratedID); err != nil {
+		return nil, fmt.Errorf("parse consumer groups: %w", err)
+	}
+	if len(info.Groups) == 0 {
+		return info, nil
+	}
+
+	var (
+		consumers = make([]*redis.Cmd, len(info.Groups))
+		summaries = make([]*redis.XPendingCmd, len(info.Groups))
+		pels      = make([]*redis.XPendingExtCmd, len(info.Groups))
+	)
+	_, err = e.rc.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, g := range info.Groups {
+			consumers[i] = p.Do(ctx, "XINFO", "CONSUMERS", key, g.Name)
+			summaries[i] = p.XPending(ctx, key, g.Name)
+			pels[i] = p.XPendingExt(ctx, &redis.XPendingExtArgs{
+				Stream: key,
+				Group:  g.Name,
+				Start:  "-",
+				End:    "+",
+				Count:  pelSize,
+			})
+		}
+		return nil
+	})
+	// Errors of the commands of a group, e.g. the nil reply of the pending summary of a group without
+	// pending entries, only leave its details empty.
+	var rerr redis.Error
+	if err != nil && !errors.As(err, &rerr) {
+		return nil, err
+	}
+
+	for i := range info.Groups {
+		g := &info.Groups[i]
+		if v, err := consumers[i].Result(); err == nil {
+			g.Consumers = parseStreamConsumers(v)
+		}
+		if v, err := summaries[i].Result(); err == nil {
+			g.Lower, g.Higher = v.Lower, v.Higher
+		}
+		if v, err := pels[i].Result(); err == nil {
+			g.PEL = v
+		}
+	}
+	return info, nil
+}
+
+// queueStreamPage queues the command fetching the page of the stream starting at the ID of the cursor,
+// and returns the function which returns the page once the command has been executed. The entries are
+// read by a raw command to keep the order of their fields.
+func queueStreamPage(ctx context.Context, c commander, key, cursor string, reverse bool) func() (interface{}, string, error) {
+	var cmd *redis.Cmd
+	if reverse {
+		if cursor == "" {
+			cursor = "+"
+		}
+		cmd = c.Do(ctx, "XREVRANGE", key, cursor, "-", "COUNT", pageSize)
+	} else {
+		if cursor == "" {
+			cursor = "-"
+		}
+		cmd = c.Do(ctx, "XRANGE", key, cursor, "+", "COUNT", pageSize)
+	}
+
+	return func() (interface{}, string, error) {
+		v, err := cmd.Result()
+		if err != nil {
+			return nil, "", err
+		}
+		entries, err := parseStreamEntries(v)
+		if err != nil || len(entries) < pageSize {
+			return entries, "", err
+		}
+		next, err := adjacentStreamID(entries[len(entries)-1].ID, reverse)
+		return entries, next, err
+	}
+}
+
+// parseStreamEntries parses the reply of XRANGE and XREVRANGE.
+func parseStreamEntries(v interface{}) ([]StreamEntry, error) {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected reply: %v", v)
+	}
+
+	entries := make([]StreamEntry, 0, len(items))
+	for _, item := range items {
+		entry, err := parseStreamEntry(item)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// parseStreamEntry parses an entry of a stream: its ID and its field-value pairs.
+func parseStreamEntry(v interface{}) (StreamEntry, error) {
+	item, ok := v.([]interface{})
+	if !ok || len(item) != 2 {
+		return StreamEntry{}, fmt.Errorf("unexpected entry: %v", v)
+	}
+	id, ok := item[0].(string)
+	if !ok {
+		return StreamEntry{}, fmt.Errorf("unexpected entry ID: %v", item[0])
+	}
+
+	// The fields of deleted entries are nil.
+	pairs, _ := item[1].([]interface{})
+	entry := StreamEntry{ID: id, Fields: make([]Field, 0, len(pairs)/2)}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		entry.Fields = append(entry.Fields, Field{Name: fmt.Sprint(pairs[i]), Value: fmt.Sprint(pairs[i+1])})
+	}
+	return entry, nil
+}
+
+// adjacentStreamID returns the ID following the stream ID, or preceding it if reverse is true. It
+// returns an empty ID past the first or the last possible ID. Exclusive ranges are only supported
+// since Redis 6.2, so the next page starts at the adjacent ID instead.
+func adjacentStreamID(id string, reverse bool) (string, error) {
+	ms, seq, err := parseStreamID(id)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case reverse && seq > 0:
+		seq--
+	case reverse && ms > 0:
+		ms, seq = ms-1, math.MaxUint64
+	case !reverse && seq < math.MaxUint64:
+		seq++
+	case !reverse && ms < math.MaxUint64:
+		ms, seq = ms+1, 0
+	default:
+		return "", nil
+	}
+	return fmt.Sprintf("%d-%d", ms, seq), nil
+}
+
+// parseStreamID returns the milliseconds and the sequence number of the stream ID.
+func parseStreamID(id string) (uint64, uint64, error) {
+	i := strings.IndexByte(id, '-')
+	if i < 0 {
+		return 0, 0, fmt.Errorf("invalid stream ID: %s", id)
+	}
+	ms, err := strconv.ParseUint(id[:i], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid stream ID: %s", id)
+	}
+	seq, err := strconv.ParseUint(id[i+1:], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid stream ID: %s", id)
+	}
+	return ms, seq, nil
+}
+
+// compareStreamIDs returns -1 if the stream ID a is lower than b, 1 if it is greater, and 0 if they
+// are equal. Invalid IDs are equal to any ID.
+func compareStreamIDs(a, b string) int {
+	ams, aseq, err := parseStreamID(a)
+	if err != nil {
+		return 0
+	}
+	bms, bseq, err := parseStreamID(b)
+	if err != nil {
+		return 0
+	}
+
+	switch {
+	case ams < bms || ams == bms && aseq < bseq:
+		return -1
+	case ams > bms || ams == bms && aseq > bseq:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// parseStreamInfo parses the reply of XINFO STREAM.
+func parseStreamInfo(v interface{}) (*StreamInfo, error) {
+	m, err := replyMap(v)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &StreamInfo{
+		Length:          replyInt(m, "length"),
+		LastGeneratedID: replyString(m, "last-generated-id"),
+		RadixTreeKeys:   replyInt(m, "radix-tree-keys"),
+		RadixTreeNodes:  replyInt(m, "radix-tree-nodes"),
+		EntriesAdded:    replyInt(m, "entries-added"),
+	}
+	if entry, err := parseStreamEntry(m["first-entry"]); err == nil {
+		info.FirstID = entry.ID
+	}
+	if entry, err := parseStreamEntry(m["last-entry"]); err == nil {
+		info.LastID = entry.ID
+	}
+	return info, nil
+}
+
+// parseStreamGroups parses the reply of XINFO GROUPS. The lag is only reported since Redis 7, and not
+// after some entries have been deleted. Otherwise it is only known if every entry has been delivered to
+// the group.
+func parseStreamGroups(v interface{}, lastGeneratedID string) ([]StreamGroup, error) {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected reply: %v", v)
+	}
+
+	groups := make([]StreamGroup, 0, len(items))
+	for _, item := range items {
+		m, err := replyMap(item)
+		if err != nil {
+			return nil, err
+		}
+		g := StreamGroup{
+			Name:            replyString(m, "name"),
+			LastDeliveredID: replyString(m, "last-delivered-id"),
+			EntriesRead:     replyInt(m, "entries-read"),
+			Lag:             replyInt(m, "lag"),
+			Pending:         replyInt(m, "pending"),
+		}
+		if g.Lag < 0 && g.LastDeliveredID == lastGeneratedID {
+			g.Lag = 0
+		}
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+// parseStreamConsumers parses the reply of XINFO CONSUMERS.
+func parseStreamConsumers(v interface{}) []StreamConsumer {
+	items, _ := v.([]interface{})
+	consumers := make([]StreamConsumer, 0, len(items))
+	for _, item := range items {
+		m, err := replyMap(item)
+		if err != nil {
+			continue
+		}
+		consumers = append(consumers, StreamConsumer{
+			Name:    replyString(m, "name"),
+			Pending: replyInt(m, "pending"),
+			Idle:    time.Duration(replyInt(m, "idle")) * time.Millisecond,
+		})
+	}
+	return consumers
+}
+
+// formatStreamID returns the stream ID, or a dash if there is none.
+func formatStreamID(id string) string {
+	if id == "" {
+		return "-"
+	}
+	return id
+}
+
+// replyMap returns the values of a reply of alternating names and values by name.
+func replyMap(v interface{}) (map[string]interface{}, error) {
+	pairs, ok := v.([]interface{})
+	if !ok || len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("unexpected reply: %v", v)
+	}
+
+	m := make(map[string]interface{}, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		m[fmt.Sprint(pairs[i])] = pairs[i+1]
+	}
+	return m, nil
+}
+
+// replyInt returns the integer value of the reply map, or -1 if it is missing or nil.
+func replyInt(m map[string]interface{}, name string) int64 {
+	switch v := m[name].(type) {
+	case int64:
+		return v
+	case string:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	}
+	return -1
+}
+
+// replyString returns the string value of the reply map, or an empty string if it is missing or nil.
+func replyString(m map[string]interface{}, name string) string {
+	if v, ok := m[name].(string); ok {
+		return v
+	}
+	return ""
+}
@@ -0,0 +1,311 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAdjacentStreamID(t *testing.T) {
+	tests := []struct {
+		id      string
+		reverse bool
+		want    string
+		wantErr bool
+	}{
+		{id: "1-1", want: "1-2"},
+		{id: "1-1", reverse: true, want: "1-0"},
+		{id: "0-0", want: "0-1"},
+		{id: "0-0", reverse: true, want: ""},
+		{id: "5-0", reverse: true, want: "4-18446744073709551615"},
+		{id: "5-18446744073709551615", want: "6-0"},
+		{id: "18446744073709551615-18446744073709551615", want: ""},
+		{id: "18446744073709551615-18446744073709551615", reverse: true, want: "18446744073709551615-18446744073709551614"},
+		{id: "", wantErr: true},
+		{id: "1", wantErr: true},
+		{id: "1-x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := adjacentStreamID(tt.id, tt.reverse)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("adjacentStreamID(%q, %v) error = %v, wantErr %v", tt.id, tt.reverse, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("adjacentStreamID(%q, %v) = %q, want %q", tt.id, tt.reverse, got, tt.want)
+		}
+	}
+}
+
+func TestParseStreamID(t *testing.T) {
+	tests := []struct {
+		id      string
+		ms, seq uint64
+		wantErr bool
+	}{
+		{id: "0-0"},
+		{id: "1526919030474-55", ms: 1526919030474, seq: 55},
+		{id: "18446744073709551615-18446744073709551615", ms: 1<<64 - 1, seq: 1<<64 - 1},
+		{id: "", wantErr: true},
+		{id: "-", wantErr: true},
+		{id: "1", wantErr: true},
+		{id: "1-", wantErr: true},
+		{id: "-1", wantErr: true},
+		{id: "1-2-3", wantErr: true},
+		{id: "a-1", wantErr: true},
+		{id: "-1-1", wantErr: true},
+		{id: "18446744073709551616-0", wantErr: true},
+		{id: "+", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		ms, seq, err := parseStreamID(tt.id)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseStreamID(%q) error = %v, wantErr %v", tt.id, err, tt.wantErr)
+			continue
+		}
+		if ms != tt.ms || seq != tt.seq {
+			t.Errorf("parseStreamID(%q) = %d, %d, want %d, %d", tt.id, ms, seq, tt.ms, tt.seq)
+		}
+	}
+}
+
+func TestCompareStreamIDs(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "1-1", b: "1-1", want: 0},
+		{a: "1-1", b: "1-2", want: -1},
+		{a: "1-2", b: "1-1", want: 1},
+		{a: "1-9", b: "2-0", want: -1},
+		{a: "10-0", b: "9-99", want: 1},
+		{a: "0-0", b: "18446744073709551615-18446744073709551615", want: -1},
+		{a: "invalid", b: "1-1", want: 0},
+		{a: "1-1", b: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := compareStreamIDs(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareStreamIDs(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestParseStreamEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   interface{}
+		want    []StreamEntry
+		wantErr bool
+	}{
+		{
+			name:  "empty",
+			reply: []interface{}{},
+			want:  []StreamEntry{},
+		},
+		{
+			name: "fields in order",
+			reply: []interface{}{
+				[]interface{}{"1-0", []interface{}{"b", "2", "a", "1"}},
+				[]interface{}{"2-0", []interface{}{"c", "3"}},
+			},
+			want: []StreamEntry{
+				{ID: "1-0", Fields: []Field{{Name: "b", Value: "2"}, {Name: "a", Value: "1"}}},
+				{ID: "2-0", Fields: []Field{{Name: "c", Value: "3"}}},
+			},
+		},
+		{
+			name:  "deleted entry",
+			reply: []interface{}{[]interface{}{"1-0", nil}},
+			want:  []StreamEntry{{ID: "1-0", Fields: []Field{}}},
+		},
+		{
+			name:    "not an array",
+			reply:   "1-0",
+			wantErr: true,
+		},
+		{
+			name:    "missing fields",
+			reply:   []interface{}{[]interface{}{"1-0"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid ID",
+			reply:   []interface{}{[]interface{}{int64(1), nil}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStreamEntries(tt.reply)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseStreamEntries() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseStreamEntries() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStreamInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   interface{}
+		want    *StreamInfo
+		wantErr bool
+	}{
+		{
+			name: "redis 6",
+			reply: []interface{}{
+				"length", int64(2),
+				"radix-tree-keys", int64(1),
+				"radix-tree-nodes", int64(2),
+				"last-generated-id", "2-0",
+				"groups", int64(1),
+				"first-entry", []interface{}{"1-0", []interface{}{"a", "1"}},
+				"last-entry", []interface{}{"2-0", []interface{}{"b", "2"}},
+			},
+			want: &StreamInfo{
+				Length:          2,
+				FirstID:         "1-0",
+				LastID:          "2-0",
+				LastGeneratedID: "2-0",
+				RadixTreeKeys:   1,
+				RadixTreeNodes:  2,
+				EntriesAdded:    -1,
+			},
+		},
+		{
+			name: "redis 7",
+			reply: []interface{}{
+				"length", int64(1),
+				"radix-tree-keys", int64(1),
+				"radix-tree-nodes", int64(2),
+				"last-generated-id", "3-0",
+				"max-deleted-entry-id", "2-0",
+				"entries-added", int64(3),
+				"recorded-first-entry-id", "3-0",
+				"groups", int64(0),
+				"first-entry", []interface{}{"3-0", []interface{}{"c", "3"}},
+				"last-entry", []interface{}{"3-0", []interface{}{"c", "3"}},
+			},
+			want: &StreamInfo{
+				Length:          1,
+				FirstID:         "3-0",
+				LastID:          "3-0",
+				LastGeneratedID: "3-0",
+				RadixTreeKeys:   1,
+				RadixTreeNodes:  2,
+				EntriesAdded:    3,
+			},
+		},
+		{
+			name: "empty stream",
+			reply: []interface{}{
+				"length", int64(0),
+				"radix-tree-keys", int64(0),
+				"radix-tree-nodes", int64(1),
+				"last-generated-id", "0-0",
+				"entries-added", int64(0),
+				"groups", int64(0),
+				"first-entry", nil,
+				"last-entry", nil,
+			},
+			want: &StreamInfo{
+				LastGeneratedID: "0-0",
+				RadixTreeNodes:  1,
+			},
+		},
+		{
+			name:    "odd reply",
+			reply:   []interface{}{"length"},
+			wantErr: true,
+		},
+		{
+			name:    "not an array",
+			reply:   "stream",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStreamInfo(tt.reply)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseStreamInfo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseStreamInfo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStreamGroups(t *testing.T) {
+	tests := []struct {
+		name            string
+		reply           interface{}
+		lastGeneratedID string
+		want            []StreamGroup
+		wantErr         bool
+	}{
+		{
+			name: "redis 6 behind",
+			reply: []interface{}{
+				[]interface{}{"name", "g", "consumers", int64(1), "pending", int64(2), "last-delivered-id", "1-0"},
+			},
+			lastGeneratedID: "2-0",
+			want:            []StreamGroup{{Name: "g", LastDeliveredID: "1-0", EntriesRead: -1, Lag: -1, Pending: 2}},
+		},
+		{
+			name: "redis 6 delivered",
+			reply: []interface{}{
+				[]interface{}{"name", "g", "consumers", int64(0), "pending", int64(0), "last-delivered-id", "2-0"},
+			},
+			lastGeneratedID: "2-0",
+			want:            []StreamGroup{{Name: "g", LastDeliveredID: "2-0", EntriesRead: -1, Lag: 0}},
+		},
+		{
+			name: "redis 7",
+			reply: []interface{}{
+				[]interface{}{"name", "a", "consumers", int64(1), "pending", int64(1), "last-delivered-id", "1-0", "entries-read", int64(1), "lag", int64(2)},
+				[]interface{}{"name", "b", "consumers", int64(0), "pending", int64(0), "last-delivered-id", "0-0", "entries-read", nil, "lag", nil},
+			},
+			lastGeneratedID: "3-0",
+			want: []StreamGroup{
+				{Name: "a", LastDeliveredID: "1-0", EntriesRead: 1, Lag: 2, Pending: 1},
+				{Name: "b", LastDeliveredID: "0-0", EntriesRead: -1, Lag: -1},
+			},
+		},
+		{
+			name:  "no groups",
+			reply: []interface{}{},
+			want:  []StreamGroup{},
+		},
+		{
+			name:    "not an array",
+			reply:   "groups",
+			wantErr: true,
+		},
+		{
+			name:    "odd group",
+			reply:   []interface{}{[]interface{}{"name"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStreamGroups(tt.reply, tt.lastGeneratedID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseStreamGroups() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseStreamGroups() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package scanner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+	"github.com/milonoir/rv/common"
+	r "github.com/milonoir/rv/redis"
+)
+
+const (
+	// indentWidth is the indentation of each level of the tree.
+	indentWidth = 2
+)
+
+// treeNode is either a namespace or a key in the tree.
+type treeNode struct {
+	name     string
+	key      string
+	depth    int
+	count    int
+	leaf     bool
+	expanded bool
+	parent   *treeNode
+	children []*treeNode
+	// namespaces indexes the namespace children by name while building the tree.
+	namespaces map[string]*treeNode
+}
+
+// child returns the namespace child with the name, creating it if needed.
+func (n *treeNode) child(name string) *treeNode {
+	if c, ok := n.namespaces[name]; ok {
+		return c
+	}
+	c := &treeNode{
+		name:       name,
+		depth:      n.depth + 1,
+		parent:     n,
+		namespaces: make(map[string]*treeNode),
+	}
+	n.namespaces[name] = c
+	n.children = append(n.children, c)
+	return c
+}
+
+// sort orders the namespaces before the keys, both by name in the sort mode, recursively.
+func (n *treeNode) sort(mode common.SortMode) {
+	sort.Slice(n.children, func(i, j int) bool {
+		a, b := n.children[i], n.children[j]
+		if a.leaf != b.leaf {
+			return !a.leaf
+		}
+		return mode.Less(a.name, b.name)
+	})
+	for _, c := range n.children {
+		c.sort(mode)
+	}
+}
+
+// tree implements the Tree interface.
+type tree struct {
+	*widgets.List
+
+	delimiter string
+	sortMode  common.SortMode
+	root      *treeNode
+	nodes     []*treeNode
+	rtype     r.DataType
+	types     map[string]r.DataType
+	rts       map[r.DataType]string
+	mtx       sync.Mutex
+}
+
+// NewTree returns a tree widget grouping the keys into namespaces separated by the delimiter.
+// Namespaces and keys are ordered by name in the sort mode.
+func NewTree(delimiter string, mode common.SortMode) *tree {
+	t := &tree{
+		List:      widgets.NewList(),
+		delimiter: delimiter,
+		sortMode:  mode,
+	}
+	t.Title = " Keyspace tree "
+	t.SelectedRowStyle = ui.NewStyle(ui.ColorWhite, ui.ColorBlue)
+
+	return t
+}
+
+func (t *tree) Update() {
+	ui.Render(t)
+}
+
+func (t *tree) Resize(x1, y1, x2, y2 int) {
+	t.SetRect(x1, y1, x2, y2)
+}
+
+func (t *tree) Close() {}
+
+// SetItems implements the Tree interface.
+func (t *tree) SetItems(items []string, rtype r.DataType, types map[string]r.DataType) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.rtype = rtype
+	t.types = types
+	t.rts = make(map[r.DataType]string)
+	t.root = &treeNode{
+		depth:      -1,
+		expanded:   true,
+		namespaces: make(map[string]*treeNode),
+	}
+	for _, item := range items {
+		n := t.root
+		n.count++
+		segments := strings.Split(item, t.delimiter)
+		for _, s := range segments[:len(segments)-1] {
+			n = n.child(s)
+			n.count++
+		}
+		n.children = append(n.children, &treeNode{
+			name:   item,
+			key:    item,
+			depth:  n.depth + 1,
+			leaf:   true,
+			parent: n,
+		})
+	}
+	t.root.sort(t.sortMode)
+
+	t.Title = fmt.Sprintf(" Keyspace tree - %d keys, delimiter %q ", len(items), t.delimiter)
+	t.SelectedRow = 0
+	t.render()
+}
+
+// SetSortMode implements the Tree interface.
+func (t *tree) SetSortMode(mode common.SortMode) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.sortMode = mode
+	if t.root == nil {
+		return
+	}
+	n := t.selected()
+	t.root.sort(mode)
+	t.render()
+	t.selectNode(n)
+}
+
+// render flattens the expanded nodes into the list rows. Must be called with the lock held.
+func (t *tree) render() {
+	t.nodes = t.nodes[:0]
+	t.Rows = t.Rows[:0]
+	t.flatten(t.root)
+}
+
+// flatten appends the rows of the children of the node.
+func (t *tree) flatten(n *treeNode) {
+	for _, c := range n.children {
+		t.nodes = append(t.nodes, c)
+		t.Rows = append(t.Rows, t.renderRow(c))
+		if c.expanded {
+			t.flatten(c)
+		}
+	}
+}
+
+func (t *tree) renderRow(n *treeNode) string {
+	indent := strings.Repeat(" ", n.depth*indentWidth)
+	if n.leaf {
+		return fmt.Sprintf("%s  %s %s", indent, t.renderType(t.itemType(n.key)), common.EscapeMarkup(n.key))
+	}
+
+	marker := "▸"
+	if n.expanded {
+		marker = "▾"
+	}
+	return fmt.Sprintf("%s%s [%s](fg:cyan) [(%d)](fg:yellow)", indent, marker, common.EscapeMarkup(n.name+t.delimiter), n.count)
+}
+
+func (t *tree) renderType(rt r.DataType) string {
+	s, ok := t.rts[rt]
+	if !ok {
+		s = fmt.Sprintf("[%*s](fg:green)", -typeWidth, strings.ToUpper(string(rt)))
+		t.rts[rt] = s
+	}
+	return s
+}
+
+// itemType returns the detected type of the item if there is one, the data type of the tree otherwise.
+func (t *tree) itemType(item string) r.DataType {
+	if rt, ok := t.types[item]; ok {
+		return rt
+	}
+	return t.rtype
+}
+
+// Select implements the Tree interface.
+func (t *tree) Select() (string, r.DataType, bool) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	n := t.selected()
+	if n == nil || !n.leaf {
+		return "", "", false
+	}
+	return n.key, t.itemType(n.key), true
+}
+
+// Toggle implements the Tree interface.
+func (t *tree) Toggle() {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if n := t.selected(); n != nil && !n.leaf {
+		t.setExpanded(n, !n.expanded)
+	}
+}
+
+// Expand implements the Tree interface.
+func (t *tree) Expand() {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if n := t.selected(); n != nil && !n.leaf {
+		t.setExpanded(n, true)
+	}
+}
+
+// Collapse implements the Tree interface.
+func (t *tree) Collapse() {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	n := t.selected()
+	if n == nil {
+		return
+	}
+	// Collapse the parent of keys and collapsed namespaces.
+	if n.leaf || !n.expanded {
+		n = n.parent
+	}
+	if n != t.root {
+		t.setExpanded(n, false)
+	}
+}
+
+// setExpanded expands or collapses the namespace and selects it. Must be called with the lock held.
+func (t *tree) setExpanded(n *treeNode, expanded bool) {
+	n.expanded = expanded
+	t.render()
+	t.selectNode(n)
+}
+
+// selectNode selects the row of the node if it is visible. Must be called with the lock held.
+func (t *tree) selectNode(n *treeNode) {
+	for i, c := range t.nodes {
+		if c == n {
+			t.SelectedRow = i
+			return
+		}
+	}
+}
+
+// selected returns the selected node. Must be called with the lock held.
+func (t *tree) selected() *treeNode {
+	if t.SelectedRow < 0 || t.SelectedRow >= len(t.nodes) {
+		return nil
+	}
+	return t.nodes[t.SelectedRow]
+}
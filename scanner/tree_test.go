@@ -0,0 +1,235 @@
+package scanner
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/milonoir/rv/common"
+	r "github.com/milonoir/rv/redis"
+)
+
+// plainRows returns the text of the rows without the style markup.
+func plainRows(rows []string) []string {
+	plain := make([]string, len(rows))
+	for i, row := range rows {
+		var b []rune
+		for _, c := range ui.ParseStyles(row, ui.StyleClear) {
+			b = append(b, c.Rune)
+		}
+		plain[i] = string(b)
+	}
+	return plain
+}
+
+func newTestTree(items []string) *tree {
+	t := NewTree(":", common.SortNatural)
+	t.SetItems(items, r.TypeKey, map[string]r.DataType{"user:1": r.TypeHash})
+	return t
+}
+
+func TestTreeSetItems(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []string
+		want  []string
+	}{
+		{
+			name:  "empty",
+			items: nil,
+			want:  []string{},
+		},
+		{
+			name:  "keys without namespace",
+			items: []string{"b", "a10", "a2"},
+			want:  []string{"  KEY    a2", "  KEY    a10", "  KEY    b"},
+		},
+		{
+			name:  "namespaces before keys",
+			items: []string{"plain", "user:2", "session:a:x", "user:1"},
+			want:  []string{"▸ session: (1)", "▸ user: (2)", "  KEY    plain"},
+		},
+		{
+			name:  "markup in names",
+			items: []string{"a[b]:c", "[x](bg:red)", "[y]"},
+			want:  []string{"▸ ⁅x⁆(bg: (1)", "▸ a⁅b⁆: (1)", "  KEY    ⁅y⁆"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newTestTree(tt.items)
+			if got := plainRows(tr.Rows); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rows = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTreeExpandCollapse(t *testing.T) {
+	items := []string{"user:1", "user:2", "user:x:1", "session:a", "plain"}
+
+	tests := []struct {
+		name     string
+		ops      func(*tree)
+		want     []string
+		selected int
+	}{
+		{
+			name: "collapsed",
+			ops:  func(*tree) {},
+			want: []string{"▸ session: (1)", "▸ user: (3)", "  KEY    plain"},
+		},
+		{
+			name: "expand",
+			ops: func(t *tree) {
+				t.ScrollDown()
+				t.Expand()
+			},
+			want: []string{
+				"▸ session: (1)",
+				"▾ user: (3)",
+				"  ▸ x: (1)",
+				"    HASH   user:1",
+				"    KEY    user:2",
+				"  KEY    plain",
+			},
+			selected: 1,
+		},
+		{
+			name: "expand nested",
+			ops: func(t *tree) {
+				t.ScrollDown()
+				t.Toggle()
+				t.ScrollDown()
+				t.Toggle()
+			},
+			want: []string{
+				"▸ session: (1)",
+				"▾ user: (3)",
+				"  ▾ x: (1)",
+				"      KEY    user:x:1",
+				"    HASH   user:1",
+				"    KEY    user:2",
+				"  KEY    plain",
+			},
+			selected: 2,
+		},
+		{
+			name: "collapse the parent of a key",
+			ops: func(t *tree) {
+				t.ScrollDown()
+				t.Expand()
+				t.ScrollBottom()
+				t.ScrollUp()
+				t.Collapse()
+			},
+			want:     []string{"▸ session: (1)", "▸ user: (3)", "  KEY    plain"},
+			selected: 1,
+		},
+		{
+			name: "collapse a collapsed namespace at the top level",
+			ops: func(t *tree) {
+				t.Collapse()
+			},
+			want: []string{"▸ session: (1)", "▸ user: (3)", "  KEY    plain"},
+		},
+		{
+			name: "toggle twice",
+			ops: func(t *tree) {
+				t.Toggle()
+				t.Toggle()
+			},
+			want: []string{"▸ session: (1)", "▸ user: (3)", "  KEY    plain"},
+		},
+		{
+			name: "keep expanded namespaces when sorting",
+			ops: func(t *tree) {
+				t.ScrollDown()
+				t.Expand()
+				t.SetSortMode(common.SortLexicographic)
+			},
+			want: []string{
+				"▸ session: (1)",
+				"▾ user: (3)",
+				"  ▸ x: (1)",
+				"    HASH   user:1",
+				"    KEY    user:2",
+				"  KEY    plain",
+			},
+			selected: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newTestTree(items)
+			tt.ops(tr)
+			if got := plainRows(tr.Rows); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rows = %q, want %q", got, tt.want)
+			}
+			if tr.SelectedRow != tt.selected {
+				t.Errorf("selected row = %d, want %d", tr.SelectedRow, tt.selected)
+			}
+		})
+	}
+}
+
+func TestTreeSelect(t *testing.T) {
+	items := []string{"user:1", "user:2", "plain"}
+
+	tests := []struct {
+		name   string
+		ops    func(*tree)
+		wantOK bool
+		key    string
+		rt     r.DataType
+	}{
+		{
+			name: "namespace",
+			ops:  func(*tree) {},
+		},
+		{
+			name:   "key of the tree type",
+			ops:    func(t *tree) { t.ScrollBottom() },
+			wantOK: true,
+			key:    "plain",
+			rt:     r.TypeKey,
+		},
+		{
+			name: "key of a detected type",
+			ops: func(t *tree) {
+				t.Expand()
+				t.ScrollDown()
+			},
+			wantOK: true,
+			key:    "user:1",
+			rt:     r.TypeHash,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newTestTree(items)
+			tt.ops(tr)
+			key, rt, ok := tr.Select()
+			if ok != tt.wantOK || key != tt.key || rt != tt.rt {
+				t.Errorf("Select() = %q, %q, %v, want %q, %q, %v", key, rt, ok, tt.key, tt.rt, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSelectorSetItemsKeepsItems(t *testing.T) {
+	items := []string{"c", "a", "b"}
+	s := NewSelector(context.Background(), nil, common.SortNatural)
+	s.SetItems(items, r.TypeKey, nil)
+
+	if want := []string{"c", "a", "b"}; !reflect.DeepEqual(items, want) {
+		t.Errorf("items = %q, want %q", items, want)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(s.all, want) {
+		t.Errorf("sorted items = %q, want %q", s.all, want)
+	}
+}
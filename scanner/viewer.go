@@ -3,7 +3,11 @@ package scanner
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	ui "github.com/gizak/termui/v3"
 	"github.com/gizak/termui/v3/widgets"
@@ -21,36 +25,144 @@ var (
 		"[Value](fg:cyan): %s",
 	}
 	listRenderTemplate = []string{
-		" " + headerTemplate + "   [Length](fg:cyan): %d",
+		" " + headerTemplate + "   [Length](fg:cyan): %s",
 		"[Items](fg:cyan):",
 	}
 	setRenderTemplate = []string{
-		"   " + headerTemplate + "   [Length](fg:cyan): %d",
+		"   " + headerTemplate + "   [Length](fg:cyan): %s",
 		"[Members](fg:cyan):",
 	}
 	zsetRenderTemplate = []string{
-		"   " + headerTemplate + "   [Length](fg:cyan): %d",
+		"   " + headerTemplate + "   [Length](fg:cyan): %s",
 		"[Members](fg:cyan):",
 	}
 	hashRenderTemplate = []string{
-		"  " + headerTemplate + "   [Length](fg:cyan): %d",
+		"  " + headerTemplate + "   [Length](fg:cyan): %s",
 		"[Fields](fg:cyan):",
 	}
+	streamRenderTemplate = []string{
+		" " + headerTemplate + "   [Length](fg:cyan): %s",
+		"[Entries](fg:cyan):",
+	}
+)
+
+var (
+	pageTimeout = 3 * time.Second
 )
 
+// element is an element of the value: the value of a single key, an item of a list, a member of a
+// set or a sorted set, a field of a hash, or a field of a stream entry.
+type element struct {
+	// prefix is rendered before the value, e.g. the index of a list item. indent is its visible width.
+	prefix string
+	indent int
+	// stored is the value as stored in Redis, value is the decoded value. value is the stored value
+	// if the decoding failed.
+	stored string
+	value  string
+	failed bool
+	// binary is true if the value is not printable text.
+	binary bool
+	// json is the parsed value if it is a JSON object or array.
+	json *jsonNode
+
+	// id identifies the element between fetches: the field of hashes, the member of sets and sorted
+	// sets, the index of list items, and the entry ID and the field of streams. attr is compared along with
+	// the stored value, e.g. the score of a sorted set member. change is the change since the previous
+	// fetch.
+	id     string
+	attr   string
+	change change
+}
+
+// newElement returns the element of the stored value rendered after the prefix markup.
+func newElement(prefix, id, stored string) *element {
+	return &element{
+		prefix: prefix,
+		indent: len(ui.ParseStyles(prefix, ui.StyleClear)),
+		stored: stored,
+		id:     id,
+	}
+}
+
+// snapshot is the value of a key fetched again: its first page with the metadata of the key, the
+// consumer groups of streams, then the following pages. err is the error of the fetch, if any.
+type snapshot struct {
+	ins     *Inspection
+	info    *StreamInfo
+	infoErr error
+	pages   []page
+	err     error
+}
+
+// page is a page of the value with the cursor of the next page.
+type page struct {
+	data interface{}
+	next string
+}
+
 type viewer struct {
 	*widgets.List
 
+	ctx      context.Context
 	executor Executor
 	err      chan string
+
+	// State of the paginated value.
+	key      string
+	rt       r.DataType
+	metadata Metadata
+	loaded   int
+	cursor   string
+	more     bool
+	seen     map[string]struct{}
+	// reverse is true if streams are shown from the newest entry.
+	reverse bool
+
+	// elements holds the loaded elements of the value, rendered after the header rows.
+	elements []*element
+	header   int
+	// refs holds the JSON node of each row which can be folded, or nil.
+	refs []*jsonNode
+	// raw is true if JSON values are shown as they are stored.
+	raw bool
+	// hex is true if binary values are shown as hex dumps instead of escaped strings.
+	hex bool
+
+	// pipelines are the decoder pipelines cycled through, pipeline is the index of the applied one.
+	decoders  *Decoders
+	pipelines []Pipeline
+	pipeline  int
+	failed    int
+
+	// removed holds the elements removed since the previous fetch. tracked is true if the value has
+	// been fetched again, and the changes are flagged.
+	removed  []*element
+	tracked  bool
+	watching bool
+	paused   bool
+	interval time.Duration
+	fetched  time.Time
+
+	// stopWatch cancels the fetch of the watched value in progress in the background, and next holds
+	// its result until the next update.
+	mtx       sync.Mutex
+	stopWatch context.CancelFunc
+	next      *snapshot
 }
 
-func NewViewer(rc *redis.Client) *viewer {
+// NewViewer returns a viewer widget. Watched values are fetched again every interval.
+func NewViewer(ctx context.Context, rc redis.UniversalClient, decoders *Decoders, interval time.Duration) *viewer {
 	v := &viewer{
 		List:     widgets.NewList(),
+		ctx:      ctx,
 		executor: newExecutor(rc),
 		err:      make(chan string, 1),
+		decoders: decoders,
+		hex:      true,
+		interval: interval,
 	}
+	v.pipelines = v.cycle(nil)
 	v.Title = " Details "
 	v.SelectedRowStyle = ui.NewStyle(ui.ColorWhite, ui.ColorBlue)
 
@@ -58,7 +170,26 @@ func NewViewer(rc *redis.Client) *viewer {
 }
 
 // Update implements the common.Widget interface.
+// Watched values are fetched again in the background once the interval has elapsed, and shown by the
+// update following the fetch. The next page of the value is fetched when the selection gets close to
+// the last row.
 func (v *viewer) Update() {
+	v.mtx.Lock()
+	s := v.next
+	v.next = nil
+	v.mtx.Unlock()
+	if s != nil {
+		v.stopWatch = nil
+		v.apply(s)
+	}
+	if v.watching && v.stopWatch == nil && time.Since(v.fetched) >= v.interval {
+		v.watch()
+	}
+	if v.more && v.SelectedRow >= len(v.Rows)-v.Inner.Dy() {
+		ctx, cancel := context.WithTimeout(v.ctx, pageTimeout)
+		v.fetch(ctx)
+		cancel()
+	}
 	ui.Render(v)
 }
 
@@ -68,98 +199,632 @@ func (v *viewer) Resize(x1, y1, x2, y2 int) {
 }
 
 // Close implements the common.Widget interface.
-func (v *viewer) Close() {}
+func (v *viewer) Close() {
+	v.cancelWatch()
+	close(v.err)
+}
 
 // View implements the Viewer interface.
+// The type of the key is detected if it is not known. Only the first page of lists, sets, sorted
+// sets, hashes and streams is fetched, the rest is fetched while scrolling.
 func (v *viewer) View(ctx context.Context, key string, rt r.DataType) {
-	ret, err := v.executor.Execute(ctx, key, rt)
+	if rt == r.TypeAuto {
+		t, err := v.executor.Type(ctx, key)
+		if err != nil {
+			v.sendErr(err.Error())
+			return
+		}
+		rt = t
+	}
+
+	v.cancelWatch()
+	v.watching, v.paused, v.tracked, v.removed = false, false, false, nil
+	v.reverse = false
+	v.SelectedRow = 0
+	v.load(ctx, key, rt)
+}
+
+// load fetches the first page of the value with the metadata of the key, and the consumer groups of
+// streams. It returns false if the fetch failed.
+func (v *viewer) load(ctx context.Context, key string, rt r.DataType) bool {
+	s := v.read(ctx, key, rt, v.reverse, 0, true)
+	if s.err != nil {
+		v.sendErr(s.err.Error())
+		return false
+	}
+	v.show(key, rt, s)
+	return true
+}
+
+// read fetches the first page of the value with the metadata of the key and the consumer groups of
+// streams, then the following pages until at least loaded elements have been fetched. Every command has
+// its own timeout. The serialized size is only fetched if dump is true. The state of the viewer is not
+// changed, so that watched values can be read in the background.
+func (v *viewer) read(ctx context.Context, key string, rt r.DataType, reverse bool, loaded int, dump bool) *snapshot {
+	c, cancel := context.WithTimeout(ctx, pageTimeout)
+	ins, err := v.executor.Inspect(c, key, rt, reverse, dump)
+	cancel()
+	if err != nil {
+		return &snapshot{err: err}
+	}
+
+	s := &snapshot{ins: ins}
+	if rt == r.TypeStream {
+		c, cancel = context.WithTimeout(ctx, pageTimeout)
+		s.info, s.infoErr = v.executor.StreamInfo(c, key)
+		cancel()
+	}
+	n, cursor := pageLen(ins.Data), ins.Cursor
+	for cursor != "" && n < loaded {
+		c, cancel = context.WithTimeout(ctx, pageTimeout)
+		data, next, err := v.executor.Execute(c, key, rt, cursor, reverse)
+		cancel()
+		if err != nil {
+			s.err = err
+			return s
+		}
+		s.pages = append(s.pages, page{data: data, next: next})
+		n, cursor = n+pageLen(data), next
+	}
+	return s
+}
+
+// show shows the value of the snapshot in place of the loaded value.
+func (v *viewer) show(key string, rt r.DataType, s *snapshot) {
+	v.key, v.rt, v.metadata = key, rt, s.ins.Metadata
+	v.loaded, v.cursor, v.more, v.failed = 0, "", false, 0
+	v.seen = make(map[string]struct{})
+	v.elements = nil
+	v.fetched = time.Now()
+	v.Rows = []string{"", v.renderMetadata()}
+	// The entries can be shown without the consumer groups.
+	if s.infoErr != nil {
+		v.sendErr(s.infoErr.Error())
+	} else if s.info != nil {
+		v.Rows = append(v.Rows, v.renderStreamInfo(s.info)...)
+	}
+	if rt != r.TypeKey {
+		// Label of the elements.
+		v.Rows = append(v.Rows, v.template()[1])
+	}
+	v.header = len(v.Rows)
+	v.refs = make([]*jsonNode, v.header)
+	v.addPage(s.ins.Data, s.ins.Cursor)
+	for _, p := range s.pages {
+		v.addPage(p.data, p.next)
+	}
+}
+
+// Refresh implements the Viewer interface.
+// The value is fetched again right away with its serialized size, instead of the watched value being
+// fetched in the background.
+func (v *viewer) Refresh(ctx context.Context) {
+	if v.key == "" {
+		return
+	}
+	v.cancelWatch()
+	v.apply(v.read(ctx, v.key, v.rt, v.reverse, v.loaded, true))
+}
+
+// ToggleOrder implements the Viewer interface.
+// The first page of the stream is fetched again in the new order.
+func (v *viewer) ToggleOrder(ctx context.Context) {
+	if v.rt != r.TypeStream {
+		return
+	}
+	v.cancelWatch()
+	v.reverse = !v.reverse
+	v.tracked, v.removed = false, nil
+	v.SelectedRow = 0
+	v.load(ctx, v.key, v.rt)
+}
+
+// ToggleWatch implements the Viewer interface.
+func (v *viewer) ToggleWatch() {
+	if v.key == "" {
+		return
+	}
+	v.watching = !v.watching
+	v.paused = !v.watching
+	if v.paused {
+		v.cancelWatch()
+	}
+	v.Rows[0] = v.renderHeader()
+}
+
+// Interval implements the Viewer interface.
+func (v *viewer) Interval() time.Duration {
+	return v.interval
+}
+
+// SetInterval implements the Viewer interface.
+func (v *viewer) SetInterval(d time.Duration) {
+	v.interval = d
+	if v.key != "" {
+		v.Rows[0] = v.renderHeader()
+	}
+}
+
+// watch starts fetching the watched value again in the background, as many elements as have been
+// loaded. DUMP is skipped, so the serialized size is unknown until the value is refreshed.
+func (v *viewer) watch() {
+	ctx, cancel := context.WithCancel(v.ctx)
+	v.stopWatch = cancel
+	key, rt, reverse, loaded := v.key, v.rt, v.reverse, v.loaded
+	go func() {
+		defer cancel()
+		s := v.read(ctx, key, rt, reverse, loaded, false)
+
+		v.mtx.Lock()
+		// Dropped if the viewed key or the order have changed meanwhile.
+		if ctx.Err() == nil {
+			v.next = s
+		}
+		v.mtx.Unlock()
+	}()
+}
+
+// cancelWatch cancels the fetch of the watched value in progress, if any, and drops its result.
+func (v *viewer) cancelWatch() {
+	if v.stopWatch != nil {
+		v.stopWatch()
+		v.stopWatch = nil
+	}
+	v.mtx.Lock()
+	v.next = nil
+	v.mtx.Unlock()
+}
+
+// apply shows the value fetched again, and flags the elements added, modified and removed since the
+// previous fetch. Watching is paused if the fetch failed, without flagging any change. If the value
+// has more elements than have been fetched again, removed elements are not flagged, and added elements
+// are only flagged if covered by the previous fetch.
+func (v *viewer) apply(s *snapshot) {
+	if s.err != nil {
+		v.sendErr(s.err.Error())
+		v.watching, v.paused = false, v.watching || v.paused
+		v.Rows[0] = v.renderHeader()
+		return
+	}
+
+	prev, row := v.elements, v.SelectedRow
+	covered := v.covered(prev)
+	v.show(v.key, v.rt, s)
+	if !v.more {
+		covered = nil
+	}
+	v.removed = diffElements(prev, v.elements, covered)
+	v.tracked = true
+	v.SelectedRow = row
+	v.render()
+	v.Rows[0] = v.renderHeader()
+}
+
+// covered returns the function which returns true if an element missing from the previous elements is
+// known to be new although the value has only been partly fetched again. This is only known of stream
+// entries, which are read in the order of their IDs: the entries up to the last previous entry are new.
+// Set, sorted set and hash scans and list indexes give no such order.
+func (v *viewer) covered(prev []*element) func(*element) bool {
+	if v.rt != r.TypeStream || len(prev) == 0 {
+		return func(*element) bool { return false }
+	}
+	last, reverse := entryID(prev[len(prev)-1]), v.reverse
+	return func(e *element) bool {
+		c := compareStreamIDs(entryID(e), last)
+		return reverse && c >= 0 || !reverse && c <= 0
+	}
+}
+
+// TogglePretty implements the Viewer interface.
+func (v *viewer) TogglePretty() {
+	v.raw = !v.raw
+	v.render()
+}
+
+// ToggleHex implements the Viewer interface.
+func (v *viewer) ToggleHex() {
+	v.hex = !v.hex
+	v.render()
+}
+
+// SetDecoders implements the Viewer interface.
+func (v *viewer) SetDecoders(names []string) error {
+	p, err := v.decoders.Pipeline(names)
+	if err != nil {
+		return err
+	}
+	v.pipelines = v.cycle(p)
+	v.pipeline = 0
+	return nil
+}
+
+// NextDecoder implements the Viewer interface.
+func (v *viewer) NextDecoder() {
+	v.pipeline = (v.pipeline + 1) % len(v.pipelines)
+	v.failed = 0
+	for _, e := range v.elements {
+		if !v.decode(e) {
+			v.failed++
+		}
+	}
+	for _, e := range v.removed {
+		v.decode(e)
+	}
+	v.render()
+	if v.header > 0 {
+		v.Rows[0] = v.renderHeader()
+	}
+}
+
+// cycle returns the pipelines to cycle through: the configured one if there is one, no decoding,
+// then the preset pipelines of the decoders.
+func (v *viewer) cycle(configured Pipeline) []Pipeline {
+	var pipelines []Pipeline
+	if len(configured) > 0 {
+		pipelines = append(pipelines, configured)
+	}
+	pipelines = append(pipelines, nil)
+	for _, p := range v.decoders.Presets() {
+		if p.String() != configured.String() {
+			pipelines = append(pipelines, p)
+		}
+	}
+	return pipelines
+}
+
+// decode decodes the stored value of the element with the applied pipeline, and returns false if the
+// decoding failed. The stored value is shown if the decoding fails.
+func (v *viewer) decode(e *element) bool {
+	value, err := v.pipelines[v.pipeline].Decode(e.stored)
+	e.failed = err != nil
+	if e.failed {
+		value = e.stored
+	}
+	e.value = value
+	e.binary = isBinary(value)
+	e.json = parseJSON(value)
+	return !e.failed
+}
+
+// ToggleFold implements the Viewer interface.
+func (v *viewer) ToggleFold() {
+	if v.SelectedRow >= len(v.refs) || v.refs[v.SelectedRow] == nil {
+		return
+	}
+	n := v.refs[v.SelectedRow]
+	n.folded = !n.folded
+	v.render()
+}
+
+// fetch fetches the next page of the value.
+func (v *viewer) fetch(ctx context.Context) {
+	ret, next, err := v.executor.Execute(ctx, v.key, v.rt, v.cursor, v.reverse)
 	if err != nil {
+		v.more = false
 		v.sendErr(err.Error())
 		return
 	}
-	switch rt {
+	v.addPage(ret, next)
+}
+
+// addPage renders the elements of the page, and the header with the cursor of the next page.
+func (v *viewer) addPage(ret interface{}, next string) {
+
+	var elements []*element
+	switch v.rt {
 	case r.TypeSortedSet:
 		data, ok := ret.([]redis.Z)
 		if !ok {
 			v.sendErr(fmt.Sprintf("executor: zset data error: %v", ret))
 			return
 		}
-		v.renderSortedSet(key, data)
+		elements = v.sortedSetElements(data)
 	case r.TypeHash:
-		data, ok := ret.(map[string]string)
+		data, ok := ret.([]Field)
 		if !ok {
 			v.sendErr(fmt.Sprintf("executor: hash data error: %v", ret))
 			return
 		}
-		v.renderHash(key, data)
+		elements = v.hashElements(data)
+	case r.TypeStream:
+		data, ok := ret.([]StreamEntry)
+		if !ok {
+			v.sendErr(fmt.Sprintf("executor: stream data error: %v", ret))
+			return
+		}
+		elements = v.streamElements(data)
 	default:
 		data, ok := ret.([]string)
 		if !ok {
-			v.sendErr(fmt.Sprintf("executor: %s data error: %v", rt, ret))
+			v.sendErr(fmt.Sprintf("executor: %s data error: %v", v.rt, ret))
 			return
 		}
-		v.renderStrings(key, data, rt)
+		elements = v.stringElements(data)
 	}
+
+	for _, e := range elements {
+		if !v.decode(e) {
+			v.failed++
+		}
+		v.elements = append(v.elements, e)
+		v.renderElement(e)
+	}
+	v.cursor, v.more = next, next != ""
+	v.Rows[0] = v.renderHeader()
 }
 
-func (v *viewer) renderStrings(key string, data []string, rt r.DataType) {
-	switch rt {
+// template returns the render template of the data type.
+func (v *viewer) template() []string {
+	switch v.rt {
 	case r.TypeList:
-		v.renderList(key, data)
+		return listRenderTemplate
 	case r.TypeSet:
-		v.renderSet(key, data)
+		return setRenderTemplate
+	case r.TypeSortedSet:
+		return zsetRenderTemplate
+	case r.TypeHash:
+		return hashRenderTemplate
+	case r.TypeStream:
+		return streamRenderTemplate
 	default:
-		v.renderKey(key, data[0])
+		return keyRenderTemplate
+	}
+}
+
+// renderHeader returns the header row with the total number of elements, and the number of loaded
+// elements while there are more pages.
+func (v *viewer) renderHeader() string {
+	var h string
+	if v.rt == r.TypeKey {
+		h = fmt.Sprintf(keyRenderTemplate[0], strings.ToUpper(string(r.TypeKey)), escape(v.key))
+	} else {
+		h = fmt.Sprintf(v.template()[0], strings.ToUpper(string(v.rt)), escape(v.key), formatCount(v.metadata.Length))
+	}
+	if v.more {
+		h += fmt.Sprintf("   [Loaded](fg:cyan): %d", v.loaded)
+	}
+	if v.reverse {
+		h += "   [Order](fg:cyan): newest first"
+	}
+	if p := v.pipelines[v.pipeline]; len(p) > 0 {
+		h += fmt.Sprintf("   [Decoder](fg:cyan): %s", p)
 	}
+	if v.failed > 0 {
+		h += fmt.Sprintf("   [Failed](fg:red): %d", v.failed)
+	}
+	switch {
+	case v.watching:
+		h += fmt.Sprintf("   [Watching](fg:green): every %s", v.interval)
+	case v.paused:
+		h += "   [Watching](fg:yellow): paused"
+	}
+	if v.tracked {
+		a, m, d := countChanges(v.elements, v.removed)
+		h += fmt.Sprintf("   [Changes](fg:cyan): +%d ~%d -%d   [Updated](fg:cyan): %s", a, m, d, v.fetched.Format("15:04:05"))
+	}
+	return h
 }
 
-func (v *viewer) renderKey(key, data string) {
-	v.Rows = []string{
-		fmt.Sprintf(keyRenderTemplate[0], strings.ToUpper(string(r.TypeKey)), key),
-		fmt.Sprintf(keyRenderTemplate[1], data),
+// renderMetadata returns the metadata row. The idle time is replaced by the access frequency with an
+// LFU eviction policy.
+func (v *viewer) renderMetadata() string {
+	md := v.metadata
+	header := v.template()[0]
+	indent := strings.Repeat(" ", len(header)-len(strings.TrimLeft(header, " "))+1)
+
+	row := fmt.Sprintf("%s[TTL](fg:cyan): %s", indent, formatTTL(md.TTL))
+	if md.TTL >= 0 {
+		row += fmt.Sprintf("   [PTTL](fg:cyan): %dms", md.TTL.Milliseconds())
+	}
+	row += fmt.Sprintf("   [Encoding](fg:cyan): %s", formatEncoding(md.Encoding))
+	if md.Freq >= 0 {
+		row += fmt.Sprintf("   [Freq](fg:cyan): %d", md.Freq)
+	} else {
+		row += fmt.Sprintf("   [Idle](fg:cyan): %s", formatIdle(md.Idle))
 	}
+	return row + fmt.Sprintf("   [Memory](fg:cyan): %s   [Serialized](fg:cyan): %s", formatBytes(md.Memory), formatBytes(md.Serialized))
 }
 
-func (v *viewer) renderList(key string, data []string) {
-	l := len(data)
-	v.Rows = make([]string, l+2)
-	v.Rows[0] = fmt.Sprintf(listRenderTemplate[0], strings.ToUpper(string(r.TypeList)), key, l)
-	v.Rows[1] = listRenderTemplate[1]
+// renderStreamInfo returns the rows of the stream info: a row of the stream, then a row of every
+// consumer group followed by the rows of its consumers and of its oldest pending entries.
+func (v *viewer) renderStreamInfo(info *StreamInfo) []string {
+	header := v.template()[0]
+	indent := strings.Repeat(" ", len(header)-len(strings.TrimLeft(header, " "))+1)
+
+	rows := []string{fmt.Sprintf("%s[First](fg:cyan): %s   [Last](fg:cyan): %s   [Last generated](fg:cyan): %s   [Added](fg:cyan): %s   [Groups](fg:cyan): %d",
+		indent, formatStreamID(info.FirstID), formatStreamID(info.LastID), formatStreamID(info.LastGeneratedID),
+		formatCount(info.EntriesAdded), len(info.Groups))}
+	for _, g := range info.Groups {
+		row := fmt.Sprintf("%s  [Group](fg:magenta): %s   [Consumers](fg:cyan): %d   [Pending](fg:cyan): %s",
+			indent, escape(g.Name), len(g.Consumers), formatCount(g.Pending))
+		if g.Pending > 0 && g.Lower != "" {
+			row += fmt.Sprintf(" (%s … %s)", g.Lower, g.Higher)
+		}
+		rows = append(rows, row+fmt.Sprintf("   [Last delivered](fg:cyan): %s   [Lag](fg:cyan): %s",
+			formatStreamID(g.LastDeliveredID), formatCount(g.Lag)))
+
+		for _, c := range g.Consumers {
+			rows = append(rows, fmt.Sprintf("%s    [Consumer](fg:cyan): %s   [Pending](fg:cyan): %d   [Idle](fg:cyan): %s",
+				indent, escape(c.Name), c.Pending, formatIdle(c.Idle)))
+		}
+		for _, p := range g.PEL {
+			rows = append(rows, fmt.Sprintf("%s    [Pending entry](fg:yellow): %s   [Consumer](fg:cyan): %s   [Idle](fg:cyan): %s   [Deliveries](fg:cyan): %d",
+				indent, p.ID, escape(p.Consumer), formatIdle(p.Idle), p.RetryCount))
+		}
+		if more := g.Pending - int64(len(g.PEL)); len(g.PEL) > 0 && more > 0 {
+			rows = append(rows, fmt.Sprintf("%s    [… %d more pending](fg:yellow)", indent, more))
+		}
+	}
+	return rows
+}
+
+// render renders the rows of every loaded element again, then the removed elements, keeping the
+// selected row.
+func (v *viewer) render() {
+	v.Rows = v.Rows[:v.header]
+	v.refs = v.refs[:v.header]
+	for _, e := range v.elements {
+		v.renderElement(e)
+	}
+	for _, e := range v.removed {
+		v.renderElement(e)
+	}
+	if v.SelectedRow >= len(v.Rows) {
+		v.SelectedRow = len(v.Rows) - 1
+	}
+}
+
+// renderElement appends the rows of the element. JSON objects and arrays are indented across
+// multiple rows unless raw values are shown. Binary values are dumped across multiple rows in hex
+// mode, and escaped otherwise. Once the value has been fetched again, a gutter flags the changes.
+func (v *viewer) renderElement(e *element) {
+	prefix, indent := e.prefix, e.indent
+	if v.tracked {
+		prefix, indent = e.change.marker()+prefix, indent+2
+	}
+
+	var lines []string
+	switch {
+	case e.binary && v.hex:
+		lines = hexDump(e.value)
+		for range lines {
+			v.refs = append(v.refs, nil)
+		}
+	case e.binary || v.raw || e.json == nil:
+		v.Rows = append(v.Rows, prefix+escape(e.value))
+		v.refs = append(v.refs, nil)
+		return
+	default:
+		e.json.render(0, true, &lines, &v.refs)
+	}
+
+	spaces := strings.Repeat(" ", indent)
+	for i, line := range lines {
+		if i == 0 {
+			v.Rows = append(v.Rows, prefix+line)
+			continue
+		}
+		v.Rows = append(v.Rows, spaces+line)
+	}
+}
+
+func (v *viewer) stringElements(data []string) []*element {
+	switch v.rt {
+	case r.TypeList:
+		return v.listElements(data)
+	case r.TypeSet:
+		return v.setElements(data)
+	default:
+		return []*element{newElement(fmt.Sprintf(keyRenderTemplate[1], ""), "", data[0])}
+	}
+}
+
+func (v *viewer) listElements(data []string) []*element {
+	elements := make([]*element, 0, len(data))
 	for i := range data {
-		v.Rows[i+2] = fmt.Sprintf("[% 5d)](fg:cyan) %s", i, data[i])
+		elements = append(elements, newElement(fmt.Sprintf("[% 5d)](fg:cyan) ", v.loaded), strconv.Itoa(v.loaded), data[i]))
+		v.loaded++
 	}
+	return elements
 }
 
-func (v *viewer) renderSet(key string, data []string) {
-	l := len(data)
-	v.Rows = make([]string, l+2)
-	v.Rows[0] = fmt.Sprintf(setRenderTemplate[0], strings.ToUpper(string(r.TypeSet)), key, l)
-	v.Rows[1] = setRenderTemplate[1]
+func (v *viewer) setElements(data []string) []*element {
+	elements := make([]*element, 0, len(data))
 	for i := range data {
-		v.Rows[i+2] = fmt.Sprintf("   [-](fg:cyan) %s", data[i])
+		if v.isSeen(data[i]) {
+			continue
+		}
+		elements = append(elements, newElement("   [-](fg:cyan) ", data[i], data[i]))
+		v.loaded++
+	}
+	return elements
+}
+
+func (v *viewer) sortedSetElements(data []redis.Z) []*element {
+	elements := make([]*element, 0, len(data))
+	for _, z := range data {
+		member := fmt.Sprint(z.Member)
+		if v.isSeen(member) {
+			continue
+		}
+		e := newElement(fmt.Sprintf("[% 20f](fg:green) - ", z.Score), member, member)
+		e.attr = fmt.Sprint(z.Score)
+		elements = append(elements, e)
+		v.loaded++
+	}
+	return elements
+}
+
+func (v *viewer) hashElements(data []Field) []*element {
+	elements := make([]*element, 0, len(data))
+	for _, f := range data {
+		if v.isSeen(f.Name) {
+			continue
+		}
+		elements = append(elements, newElement(fmt.Sprintf("[% 20s](fg:green): ", escape(f.Name)), f.Name, f.Value))
+		v.loaded++
 	}
+	return elements
 }
 
-func (v *viewer) renderSortedSet(key string, data []redis.Z) {
-	l := len(data)
-	v.Rows = make([]string, l+2)
-	v.Rows[0] = fmt.Sprintf(zsetRenderTemplate[0], strings.ToUpper(string(r.TypeSortedSet)), key, l)
-	v.Rows[1] = zsetRenderTemplate[1]
-	for i, z := range data {
-		v.Rows[i+2] = fmt.Sprintf("[% 20f](fg:green) - %v", z.Score, z.Member)
+// streamElements returns an element for every field of the entries. The ID of the entry is rendered
+// before its first field, and the names of the fields are aligned within the entry.
+func (v *viewer) streamElements(data []StreamEntry) []*element {
+	elements := make([]*element, 0, len(data))
+	for _, entry := range data {
+		v.loaded++
+		id := fmt.Sprintf("[% 20s](fg:green) ", entry.ID)
+		if len(entry.Fields) == 0 {
+			elements = append(elements, newElement(id, entry.ID, ""))
+			continue
+		}
+
+		var width int
+		for _, f := range entry.Fields {
+			if n := utf8.RuneCountInString(escape(f.Name)); n > width {
+				width = n
+			}
+		}
+		for i, f := range entry.Fields {
+			if i == 1 {
+				id = strings.Repeat(" ", 21)
+			}
+			prefix := fmt.Sprintf("%s[%-*s](fg:cyan): ", id, width, escape(f.Name))
+			elements = append(elements, newElement(prefix, entry.ID+" "+f.Name, f.Value))
+		}
 	}
+	return elements
+}
+
+// pageLen returns the number of elements of the page, or of entries of stream pages.
+func pageLen(data interface{}) int {
+	switch d := data.(type) {
+	case []string:
+		return len(d)
+	case []redis.Z:
+		return len(d)
+	case []Field:
+		return len(d)
+	case []StreamEntry:
+		return len(d)
+	default:
+		return 0
+	}
+}
+
+// entryID returns the ID of the stream entry of the element.
+func entryID(e *element) string {
+	return strings.SplitN(e.id, " ", 2)[0]
 }
 
-func (v *viewer) renderHash(key string, data map[string]string) {
-	l := len(data)
-	v.Rows = make([]string, l+2)
-	v.Rows[0] = fmt.Sprintf(hashRenderTemplate[0], strings.ToUpper(string(r.TypeHash)), key, l)
-	v.Rows[1] = hashRenderTemplate[1]
-	i := 2
-	for field, value := range data {
-		v.Rows[i] = fmt.Sprintf("[% 20s](fg:green): %s", field, value)
-		i++
+// isSeen returns true if the element has already been rendered, and marks it as seen otherwise.
+// Scan commands may return an element more than once.
+func (v *viewer) isSeen(element string) bool {
+	if _, ok := v.seen[element]; ok {
+		return true
 	}
+	v.seen[element] = struct{}{}
+	return false
 }
 
 func (v *viewer) sendErr(err string) {
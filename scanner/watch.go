@@ -0,0 +1,93 @@
+package scanner
+
+// change is the change of an element since the previous fetch of the value.
+type change int
+
+const (
+	unchanged change = iota
+	added
+	modified
+	removed
+)
+
+// marker returns the gutter marker of the change.
+func (c change) marker() string {
+	switch c {
+	case added:
+		return "[+](fg:black,bg:green) "
+	case modified:
+		return "[~](fg:black,bg:yellow) "
+	case removed:
+		return "[-](fg:black,bg:red) "
+	default:
+		return "  "
+	}
+}
+
+// diffElements flags the elements of next added or modified since prev, and returns the elements of
+// prev which have been removed. Elements are matched by their id, so a list item set to another value
+// is modified, and the items after an inserted or removed one are modified too.
+// Unchanged elements keep the parsed JSON of their previous fetch, so that their folded objects stay
+// folded.
+//
+// covered is nil if the whole value has been fetched again. Otherwise the elements of prev missing from
+// next may still exist beyond the fetched elements, so none is flagged as removed, and the elements of
+// next missing from prev may have existed without having been loaded, so they are only flagged as added
+// if covered returns true.
+func diffElements(prev, next []*element, covered func(*element) bool) []*element {
+	byID := make(map[string]*element, len(prev))
+	for _, e := range prev {
+		byID[e.id] = e
+	}
+
+	matched := make(map[*element]bool, len(prev))
+	for _, e := range next {
+		p, ok := byID[e.id]
+		if !ok {
+			e.change = added
+			if covered != nil && !covered(e) {
+				e.change = unchanged
+			}
+			continue
+		}
+		matched[p] = true
+
+		switch {
+		case p.stored != e.stored || p.attr != e.attr:
+			e.change = modified
+		case p.value == e.value:
+			e.change = unchanged
+			if p.json != nil {
+				e.json = p.json
+			}
+		default:
+			e.change = unchanged
+		}
+	}
+
+	if covered != nil {
+		return nil
+	}
+	var gone []*element
+	for _, e := range prev {
+		if !matched[e] {
+			e.change = removed
+			gone = append(gone, e)
+		}
+	}
+	return gone
+}
+
+// countChanges returns the number of added, modified and removed elements.
+func countChanges(elements, gone []*element) (int, int, int) {
+	var a, m int
+	for _, e := range elements {
+		switch e.change {
+		case added:
+			a++
+		case modified:
+			m++
+		}
+	}
+	return a, m, len(gone)
+}
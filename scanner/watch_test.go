@@ -0,0 +1,77 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffListElements(t *testing.T) {
+	tests := []struct {
+		name        string
+		prev, next  []string
+		covered     bool
+		wantChanges []change
+		wantRemoved []string
+	}{
+		{
+			name:        "unchanged duplicates",
+			prev:        []string{"a", "a", "b"},
+			next:        []string{"a", "a", "b"},
+			wantChanges: []change{unchanged, unchanged, unchanged},
+		},
+		{
+			name:        "set",
+			prev:        []string{"a", "a", "b"},
+			next:        []string{"a", "c", "b"},
+			wantChanges: []change{unchanged, modified, unchanged},
+		},
+		{
+			name:        "pushed duplicate",
+			prev:        []string{"a", "b"},
+			next:        []string{"a", "b", "b"},
+			wantChanges: []change{unchanged, unchanged, added},
+		},
+		{
+			name:        "popped duplicate",
+			prev:        []string{"a", "a", "a"},
+			next:        []string{"a", "a"},
+			wantChanges: []change{unchanged, unchanged},
+			wantRemoved: []string{"a"},
+		},
+		{
+			name:        "partly fetched",
+			prev:        []string{"a", "b"},
+			next:        []string{"b"},
+			covered:     true,
+			wantChanges: []change{modified},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev := (&viewer{}).listElements(tt.prev)
+			next := (&viewer{}).listElements(tt.next)
+			var covered func(*element) bool
+			if tt.covered {
+				covered = func(*element) bool { return false }
+			}
+
+			gone := diffElements(prev, next, covered)
+
+			var changes []change
+			for _, e := range next {
+				changes = append(changes, e.change)
+			}
+			if !reflect.DeepEqual(changes, tt.wantChanges) {
+				t.Errorf("changes = %v, want %v", changes, tt.wantChanges)
+			}
+			var removed []string
+			for _, e := range gone {
+				removed = append(removed, e.stored)
+			}
+			if !reflect.DeepEqual(removed, tt.wantRemoved) {
+				t.Errorf("removed = %q, want %q", removed, tt.wantRemoved)
+			}
+		})
+	}
+}
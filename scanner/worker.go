@@ -3,6 +3,9 @@ package scanner
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -10,37 +13,89 @@ import (
 	r "github.com/milonoir/rv/redis"
 )
 
+const (
+	// errBufSize is the size of the buffer of the error channel.
+	errBufSize = 10
+
+	// typeBatchSize is the number of TYPE commands sent in a single pipeline.
+	typeBatchSize = 1000
+
+	// defaultIterations is the default number of scan iterations per run in incremental mode.
+	defaultIterations = 10
+
+	// historySize is the number of key count samples kept by the worker.
+	historySize = 300
+
+	// scanTypeVersion is the major version of the first Redis release supporting the TYPE option of SCAN.
+	scanTypeVersion = 6
+)
+
 // worker implements the Worker interface.
 type worker struct {
 	*Config
 
-	rc      *redis.Client
-	ctx     context.Context
-	name    string
-	enabled bool
-	reply   []string
-	updated time.Time
-	mtx     sync.Mutex
-	err     chan string
+	rc        redis.UniversalClient
+	ctx       context.Context
+	name      string
+	enabled   bool
+	reply     []string
+	added     []string
+	removed   []string
+	types     map[string]r.DataType
+	nodes     map[string]int
+	history   []Sample
+	head      int
+	truncated bool
+	alerting  bool
+	progress  *Progress
+	updated   time.Time
+	mtx       sync.Mutex
+	err       chan string
+
+	trigger       chan struct{}
+	reset         chan time.Duration
+	configure     chan Config
+	notifications sync.WaitGroup
+
+	// pass is the pass in progress in incremental mode. Only used by Run, the UI reads its progress.
+	pass *pass
+	// typeFilter caches whether the nodes support the TYPE option of SCAN, probeFailed holds the
+	// nodes whose failed version check has been reported. Only used by Run.
+	typeFilter  map[string]bool
+	probeFailed map[string]bool
+	// alerts holds whether the alert rules are firing, queued the alert messages not sent yet. Only
+	// used by Run.
+	alerts map[string]bool
+	queued []string
 }
 
 // newWorker returns a configured worker.
-func newWorker(ctx context.Context, rc *redis.Client, name string, cfg *Config) Worker {
+func newWorker(ctx context.Context, rc redis.UniversalClient, name string, cfg *Config) Worker {
 	return &worker{
 		Config:  cfg,
 		rc:      rc,
 		ctx:     ctx,
 		name:    name,
 		enabled: true,
-		err:     make(chan string, 1),
+		err:     make(chan string, errBufSize),
+		trigger: make(chan struct{}, 1),
+		reset:   make(chan time.Duration, 1),
+
+		configure: make(chan Config, 1),
+
+		typeFilter:  make(map[string]bool),
+		probeFailed: make(map[string]bool),
+		alerts:      make(map[string]bool),
 	}
 }
 
 // Run implements the Worker interface.
 func (w *worker) Run() {
-	t := time.NewTicker(w.Interval.Duration)
+	t := time.NewTicker(w.Interval())
 	defer t.Stop()
 	defer close(w.err)
+	// Alert notifications may still send errors.
+	defer w.notifications.Wait()
 
 	w.run()
 	for {
@@ -48,41 +103,331 @@ func (w *worker) Run() {
 		case <-w.ctx.Done():
 			// Worker has been aborted.
 			return
+		case <-w.trigger:
+			w.run()
+		case d := <-w.reset:
+			t.Reset(d)
+		case cfg := <-w.configure:
+			w.apply(cfg)
+			t.Reset(cfg.Interval.Duration)
 		case <-t.C:
-			if w.enabled {
+			if w.isEnabled() {
 				w.run()
 			}
 		}
 	}
 }
 
+// node is a Redis server scanned by the worker.
+type node struct {
+	addr string
+	rc   redis.Cmdable
+}
+
+// pass is the state of a complete walk of the keyspace of every node. failed is true if a node could
+// not be scanned completely.
+type pass struct {
+	nodes     []node
+	node      int
+	cursor    uint64
+	reply     []string
+	added     []string
+	removed   []string
+	types     map[string]r.DataType
+	counts    map[string]int
+	truncated bool
+	failed    bool
+}
+
+// done returns true if every node has been scanned.
+func (p *pass) done() bool {
+	return p.node >= len(p.nodes)
+}
+
 // run executes the configured Redis scan command and saves its response and time of execution.
+// In cluster mode the scan is executed on every master node and the replies are merged.
+// In auto type mode the type of every matching key is detected.
+// The scan stops when the configured maximum number of keys has been collected.
+//
+// In incremental mode only the configured number of scan iterations are executed, and the pass
+// is continued by the next run. The response is saved when the pass is complete. A pass is stopped at
+// the first error, and the previous response is kept.
 func (w *worker) run() {
-	reply := make([]string, 0, 100)
+	p := w.pass
+	if p == nil {
+		p = w.newPass()
+	}
 
-	iter := w.rc.Scan(w.ctx, 0, w.Config.Pattern, 0).Iterator()
-	for iter.Next(w.ctx) {
-		reply = append(reply, iter.Val())
+	limit := 0
+	if w.Incremental {
+		limit = w.iterations()
 	}
-	if err := iter.Err(); err != nil {
-		// Try sending the error.
-		select {
-		case w.err <- fmt.Sprintf("(worker: %s): %s", w.name, err.Error()):
-		default:
-		}
+	w.step(p, limit)
+
+	w.flushAlerts()
+	// The key count of truncated passes is not the number of matching keys.
+	if w.publish(p) && !p.truncated {
+		w.checkAlerts(w.History())
 	}
+}
 
+// publish saves the response of the pass if it is complete, or its progress otherwise. Failed passes
+// are dropped, so that their missing keys are neither reported as removed nor counted in the history.
+// Returns true if the response has been saved.
+func (w *worker) publish(p *pass) bool {
 	w.mtx.Lock()
-	w.reply = reply
+	defer w.mtx.Unlock()
+
+	if !p.done() {
+		w.pass = p
+		w.progress = &Progress{
+			Keys:  len(p.reply),
+			Node:  p.node + 1,
+			Nodes: len(p.nodes),
+		}
+		return false
+	}
+
+	w.pass, w.progress = nil, nil
+	if p.failed {
+		return false
+	}
+	if !w.updated.IsZero() {
+		w.added, w.removed = diff(w.reply, p.reply)
+	}
+	w.reply = p.reply
+	w.types = p.types
+	w.nodes = p.counts
+	w.truncated = p.truncated
 	w.updated = time.Now().Local()
-	w.mtx.Unlock()
+	w.record(Sample{Time: w.updated, Count: len(p.reply)})
+	return true
+}
+
+// record saves the sample into the history ring buffer. Must be called with the lock held.
+func (w *worker) record(sample Sample) {
+	if len(w.history) < historySize {
+		w.history = append(w.history, sample)
+		return
+	}
+	w.history[w.head] = sample
+	w.head = (w.head + 1) % historySize
+}
+
+// diff returns the keys of cur missing from prev, and the keys of prev missing from cur.
+func diff(prev, cur []string) (added, removed []string) {
+	seen := make(map[string]struct{}, len(prev))
+	for _, key := range prev {
+		seen[key] = struct{}{}
+	}
+	added = make([]string, 0)
+	for _, key := range cur {
+		if _, ok := seen[key]; ok {
+			delete(seen, key)
+			continue
+		}
+		added = append(added, key)
+	}
+	removed = make([]string, 0, len(seen))
+	for key := range seen {
+		removed = append(removed, key)
+	}
+	return added, removed
+}
+
+// newPass returns a new pass starting at the beginning of the keyspace.
+func (w *worker) newPass() *pass {
+	nodes, err := w.scanNodes()
+	if err != nil {
+		w.sendErr(err)
+		return &pass{failed: true}
+	}
+
+	p := &pass{
+		nodes:  nodes,
+		reply:  make([]string, 0, 100),
+		counts: make(map[string]int, len(nodes)),
+	}
+	if w.Type == r.TypeAuto {
+		p.types = make(map[string]r.DataType)
+	}
+	return p
+}
+
+// step executes at most limit scan iterations of the pass, or runs the pass to completion if limit is 0.
+func (w *worker) step(p *pass, limit int) {
+	for i := 0; !p.done() && (limit == 0 || i < limit); i++ {
+		if w.MaxKeys > 0 && len(p.reply) >= w.MaxKeys {
+			// The budget has been spent exactly by the previous batch, the rest of the keyspace may
+			// hold more matching keys.
+			p.truncated = true
+			p.node = len(p.nodes)
+			return
+		}
+
+		n := p.nodes[p.node]
+		keys, cursor, err := w.scan(n, p.cursor).Result()
+		if err != nil {
+			if w.ctx.Err() == nil {
+				w.sendErr(err)
+			}
+			p.node, p.failed = len(p.nodes), true
+			return
+		}
+
+		if w.MaxKeys > 0 && len(p.reply)+len(keys) > w.MaxKeys {
+			keys = keys[:w.MaxKeys-len(p.reply)]
+			p.truncated = true
+		}
+		p.reply = append(p.reply, keys...)
+		if p.types != nil {
+			if err := w.detectTypes(n, keys, p.types); err != nil {
+				if w.ctx.Err() == nil {
+					w.sendErr(err)
+				}
+				p.node, p.failed = len(p.nodes), true
+				return
+			}
+		}
+		if n.addr != "" {
+			p.counts[n.addr] += len(keys)
+		}
+
+		switch {
+		case p.truncated:
+			p.node = len(p.nodes)
+		case cursor == 0:
+			p.node, p.cursor = p.node+1, 0
+		default:
+			p.cursor = cursor
+		}
+	}
+}
+
+// scan returns the Redis scan command for the node. The configured type is passed to the TYPE option
+// if the node supports it.
+func (w *worker) scan(n node, cursor uint64) *redis.ScanCmd {
+	if w.Type != r.TypeAuto && w.supportsTypeFilter(n) {
+		return n.rc.ScanType(w.ctx, cursor, w.Config.Pattern, w.Count, w.Type.RedisType())
+	}
+	return n.rc.Scan(w.ctx, cursor, w.Config.Pattern, w.Count)
+}
+
+// supportsTypeFilter returns true if the node supports the TYPE option of the Redis scan command.
+// The result is cached after the first successful check. The option is not used while the version of
+// the node cannot be determined, and the check is repeated by the next scan. Only the first failure is
+// reported.
+func (w *worker) supportsTypeFilter(n node) bool {
+	if ok, cached := w.typeFilter[n.addr]; cached {
+		return ok
+	}
+
+	info, err := n.rc.Info(w.ctx, "server").Result()
+	if err != nil {
+		if !w.probeFailed[n.addr] {
+			w.sendErr(fmt.Errorf("detect server version: %w", err))
+			w.probeFailed[n.addr] = true
+		}
+		return false
+	}
+	ok := majorVersion(info) >= scanTypeVersion
+	w.typeFilter[n.addr] = ok
+	return ok
+}
+
+// detectTypes pipelines a Redis TYPE command for every key and saves the types into the map.
+func (w *worker) detectTypes(n node, keys []string, types map[string]r.DataType) error {
+	for i := 0; i < len(keys); i += typeBatchSize {
+		batch := keys[i:]
+		if len(batch) > typeBatchSize {
+			batch = batch[:typeBatchSize]
+		}
+
+		cmds := make([]*redis.StatusCmd, len(batch))
+		_, err := n.rc.Pipelined(w.ctx, func(p redis.Pipeliner) error {
+			for j, key := range batch {
+				cmds[j] = p.Type(w.ctx, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for j, key := range batch {
+			types[key] = r.ParseRedisType(cmds[j].Val())
+		}
+	}
+	return nil
+}
+
+// majorVersion returns the major version of the Redis server from the reply of the Redis INFO command.
+func majorVersion(info string) int {
+	for _, line := range strings.Split(info, "\n") {
+		if !strings.HasPrefix(line, "redis_version:") {
+			continue
+		}
+		v := strings.TrimSpace(strings.TrimPrefix(line, "redis_version:"))
+		major, err := strconv.Atoi(strings.SplitN(v, ".", 2)[0])
+		if err != nil {
+			return 0
+		}
+		return major
+	}
+	return 0
+}
+
+// scanNodes returns the nodes to be scanned: every master node in cluster mode, the client
+// itself otherwise.
+func (w *worker) scanNodes() ([]node, error) {
+	cc, ok := w.rc.(*redis.ClusterClient)
+	if !ok {
+		return []node{{rc: w.rc}}, nil
+	}
+
+	var (
+		nodes []node
+		mtx   sync.Mutex
+	)
+	err := cc.ForEachMaster(w.ctx, func(_ context.Context, c *redis.Client) error {
+		mtx.Lock()
+		nodes = append(nodes, node{addr: c.Options().Addr, rc: c})
+		mtx.Unlock()
+		return nil
+	})
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].addr < nodes[j].addr
+	})
+	return nodes, err
+}
+
+// sendErr tries sending the error to the error channel.
+func (w *worker) sendErr(err error) {
+	w.sendMsg(fmt.Sprintf("(worker: %s): %s", w.name, err.Error()))
+}
+
+// sendMsg tries sending the message to the error channel.
+func (w *worker) sendMsg(m string) {
+	select {
+	case w.err <- m:
+	default:
+	}
 }
 
 // Pattern implements the Worker interface.
 func (w *worker) Pattern() (string, r.DataType) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
 	return w.Config.Pattern, w.Type
 }
 
+// IsSingle implements the Worker interface.
+func (w *worker) IsSingle() bool {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return w.Config.IsSingle()
+}
+
 // LastReply implements the Worker interface.
 func (w *worker) State() ([]string, time.Time, bool) {
 	w.mtx.Lock()
@@ -93,6 +438,146 @@ func (w *worker) State() ([]string, time.Time, bool) {
 	return cpy, w.updated, w.enabled
 }
 
+// History implements the Worker interface.
+func (w *worker) History() []Sample {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	cpy := make([]Sample, 0, len(w.history))
+	cpy = append(cpy, w.history[w.head:]...)
+	return append(cpy, w.history[:w.head]...)
+}
+
+// Diff implements the Worker interface.
+func (w *worker) Diff() ([]string, []string) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	added := make([]string, len(w.added))
+	copy(added, w.added)
+	removed := make([]string, len(w.removed))
+	copy(removed, w.removed)
+	return added, removed
+}
+
+// Progress implements the Worker interface.
+func (w *worker) Progress() (Progress, bool) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	if w.progress == nil {
+		return Progress{}, false
+	}
+	return *w.progress, true
+}
+
+// Alerting implements the Worker interface.
+func (w *worker) Alerting() bool {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return w.alerting
+}
+
+// Truncated implements the Worker interface.
+func (w *worker) Truncated() bool {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return w.truncated
+}
+
+// Types implements the Worker interface.
+func (w *worker) Types() map[string]r.DataType {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	if w.types == nil {
+		return nil
+	}
+	cpy := make(map[string]r.DataType, len(w.types))
+	for key, t := range w.types {
+		cpy[key] = t
+	}
+	return cpy
+}
+
+// Nodes implements the Worker interface.
+func (w *worker) Nodes() map[string]int {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	cpy := make(map[string]int, len(w.nodes))
+	for addr, n := range w.nodes {
+		cpy[addr] = n
+	}
+	return cpy
+}
+
+// Settings implements the Worker interface.
+func (w *worker) Settings() Config {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return *w.Config
+}
+
+// Trigger implements the Worker interface.
+func (w *worker) Trigger() {
+	select {
+	case w.trigger <- struct{}{}:
+	default:
+		// A scan has already been triggered.
+	}
+}
+
+// Interval implements the Worker interface.
+func (w *worker) Interval() time.Duration {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return w.Config.Interval.Duration
+}
+
+// Configure implements the Worker interface.
+func (w *worker) Configure(cfg Config) {
+	// Replace the pending configuration, if any.
+	select {
+	case <-w.configure:
+	default:
+	}
+	w.configure <- cfg
+}
+
+// apply replaces the configuration of the worker. The state of the alert rules which are no longer
+// configured is dropped, the other rules are evaluated with their new thresholds by the next scan.
+// Only called by Run.
+func (w *worker) apply(cfg Config) {
+	w.mtx.Lock()
+	*w.Config = cfg
+	w.mtx.Unlock()
+
+	if cfg.AlertAbove == nil {
+		delete(w.alerts, ruleAbove)
+	}
+	if cfg.AlertBelow == nil {
+		delete(w.alerts, ruleBelow)
+	}
+	if cfg.AlertGrowthRate <= 0 {
+		delete(w.alerts, ruleGrowth)
+	}
+}
+
+// SetInterval implements the Worker interface.
+func (w *worker) SetInterval(d time.Duration) {
+	w.mtx.Lock()
+	w.Config.Interval.Duration = d
+	w.mtx.Unlock()
+
+	// Replace the pending reset, if any.
+	select {
+	case <-w.reset:
+	default:
+	}
+	w.reset <- d
+}
+
 // Enable implements the Worker interface.
 func (w *worker) Enable() {
 	w.mtx.Lock()
@@ -107,6 +592,13 @@ func (w *worker) Disable() {
 	w.mtx.Unlock()
 }
 
+// isEnabled returns true if the worker is enabled.
+func (w *worker) isEnabled() bool {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return w.enabled
+}
+
 // ErrCh implements the Worker interface.
 func (w *worker) ErrCh() <-chan string {
 	return w.err
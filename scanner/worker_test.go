@@ -0,0 +1,45 @@
+package scanner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/milonoir/rv/common"
+)
+
+func TestWorkerConfigure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	above, below := 10, 1
+	w := newWorker(ctx, nil, "jobs", &Config{
+		Pattern:    "job:*",
+		Interval:   common.Duration{Duration: time.Second},
+		AlertAbove: &above,
+		AlertBelow: &below,
+	}).(*worker)
+	w.record(Sample{Time: time.Now(), Count: 12})
+	w.alerts[ruleAbove], w.alerts[ruleBelow] = true, false
+
+	newAbove := 20
+	w.Configure(Config{Pattern: "job:*", Interval: common.Duration{Duration: time.Minute}, AlertAbove: &newAbove})
+	// A pending configuration is replaced.
+	cfg := Config{Pattern: "job:*", Interval: common.Duration{Duration: time.Hour}, AlertAbove: &newAbove, MaxKeys: 100}
+	w.Configure(cfg)
+	w.apply(<-w.configure)
+
+	got := w.Settings()
+	if got.Interval.Duration != time.Hour || got.MaxKeys != 100 || got.AlertAbove == nil || *got.AlertAbove != 20 || got.AlertBelow != nil {
+		t.Errorf("Settings() = %+v, want %+v", got, cfg)
+	}
+	if h := w.History(); len(h) != 1 || h[0].Count != 12 {
+		t.Errorf("History() = %v, want the sample recorded before", h)
+	}
+	if firing, ok := w.alerts[ruleAbove]; !ok || !firing {
+		t.Error("state of the configured alert rule dropped")
+	}
+	if _, ok := w.alerts[ruleBelow]; ok {
+		t.Error("state of the removed alert rule kept")
+	}
+}
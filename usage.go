@@ -0,0 +1,82 @@
+package main
+
+const (
+	scannerUsage = `  [<Up>](fg:yellow)/[<Down>](fg:yellow)   move selection up/down   [<Enter>](fg:yellow) select            [<m>](fg:yellow) view messages
+[<PgUp>](fg:yellow)/[<PgDown>](fg:yellow) scroll up/down           [<e>](fg:yellow)     enable scanner    [<?>](fg:yellow) all keys
+[<Home>](fg:yellow)/[<End>](fg:yellow)    move to top/bottom       [<d>](fg:yellow)     disable scanner   [<q>](fg:yellow) quit`
+	scannerKeys = `[<Up>](fg:yellow)/[<Down>](fg:yellow)       move selection up/down
+[<PgUp>](fg:yellow)/[<PgDown>](fg:yellow)   scroll up/down
+[<Home>](fg:yellow)/[<End>](fg:yellow)      move to top/bottom
+[<Enter>](fg:yellow)           inspect matching keys
+[<+>](fg:yellow)/[<->](fg:yellow)           inspect keys added/removed by the last scan
+[<e>](fg:yellow)/[<d>](fg:yellow)           enable/disable scanner
+[<E>](fg:yellow)/[<D>](fg:yellow)           enable/disable all scanners
+[<s>](fg:yellow)               scan now
+[<o>](fg:yellow)               scan a pattern once
+[<i>](fg:yellow)               change scan interval
+[<h>](fg:yellow)               key count history
+[<a>](fg:yellow)               add scanner
+[<c>](fg:yellow)               change scanner
+[<x>](fg:yellow)               delete scanner
+[<w>](fg:yellow)               save scanners to the config file
+[<n>](fg:yellow)               toggle node counts
+[<#>](fg:yellow)               toggle natural/lexicographic order
+[<p>](fg:yellow)               switch profile
+[<m>](fg:yellow)               view messages
+[<?>](fg:yellow)               show all keys
+[<q>](fg:yellow)               quit`
+	selectorUsage = `  [<Up>](fg:yellow)/[<Down>](fg:yellow)   move selection up/down   [<Enter>](fg:yellow) select   [</>](fg:yellow)   search
+[<PgUp>](fg:yellow)/[<PgDown>](fg:yellow) scroll up/down           [<Esc>](fg:yellow)   go back  [<n/N>](fg:yellow) jump to match
+[<Home>](fg:yellow)/[<End>](fg:yellow)    move to top/bottom       [<q>](fg:yellow)     quit     [<?>](fg:yellow)   all keys`
+	selectorKeys = `[<Up>](fg:yellow)/[<Down>](fg:yellow)       move selection up/down
+[<PgUp>](fg:yellow)/[<PgDown>](fg:yellow)   scroll up/down
+[<Home>](fg:yellow)/[<End>](fg:yellow)      move to top/bottom
+[<Enter>](fg:yellow)           inspect key
+[</>](fg:yellow)               search keys
+[<n>](fg:yellow)/[<N>](fg:yellow)           jump to next/previous match in all keys
+[<t>](fg:yellow)               tree view
+[<c>](fg:yellow)               show/hide key metadata columns
+[<s>](fg:yellow)               sort by next column
+[<S>](fg:yellow)               reverse sort order
+[<#>](fg:yellow)               toggle natural/lexicographic order
+[<Esc>](fg:yellow)             show all keys/clear highlights/go back
+[<?>](fg:yellow)               show all keys
+[<q>](fg:yellow)               quit`
+	searchUsage = `[<Enter>](fg:yellow) apply filter   [<Tab>](fg:yellow) substring/glob/regex
+  [<Esc>](fg:yellow) clear filter`
+	treeUsage = `  [<Up>](fg:yellow)/[<Down>](fg:yellow)   move selection up/down   [<Enter>](fg:yellow) select/expand   [<t>](fg:yellow) list view
+[<PgUp>](fg:yellow)/[<PgDown>](fg:yellow) scroll up/down           [<Right>](fg:yellow) expand          [<Esc>](fg:yellow) go back
+[<Home>](fg:yellow)/[<End>](fg:yellow)    move to top/bottom       [<Left>](fg:yellow)  collapse        [<q>](fg:yellow) quit`
+	viewerUsage = `  [<Up>](fg:yellow)/[<Down>](fg:yellow)   move selection up/down   [<Enter>](fg:yellow) fold JSON   [<r>](fg:yellow) refresh
+[<PgUp>](fg:yellow)/[<PgDown>](fg:yellow) scroll up/down           [<Esc>](fg:yellow)   go back     [<w>](fg:yellow) watch/pause
+[<Home>](fg:yellow)/[<End>](fg:yellow)    move to top/bottom       [<q>](fg:yellow)     quit        [<?>](fg:yellow) all keys`
+	viewerKeys = `[<Up>](fg:yellow)/[<Down>](fg:yellow)       move selection up/down
+[<PgUp>](fg:yellow)/[<PgDown>](fg:yellow)   scroll up/down
+[<Home>](fg:yellow)/[<End>](fg:yellow)      move to top/bottom
+[<Enter>](fg:yellow)/[<Space>](fg:yellow)   fold/unfold JSON object or array
+[<p>](fg:yellow)               pretty-printed/raw JSON
+[<x>](fg:yellow)               hex dump/escaped binary values
+[<d>](fg:yellow)               next decoder
+[<o>](fg:yellow)               oldest/newest stream entries first
+[<r>](fg:yellow)               refresh value and metadata, flag changes
+[<w>](fg:yellow)               watch/pause refreshing
+[<i>](fg:yellow)               change watch interval
+[<Esc>](fg:yellow)             go back
+[<?>](fg:yellow)               show all keys
+[<q>](fg:yellow)               quit`
+	messagesUsage = `[<Esc>](fg:yellow) go back
+  [<q>](fg:yellow) quit`
+	keysUsage = `[<Esc>](fg:yellow) go back
+  [<q>](fg:yellow) quit`
+	chartUsage = `[<Esc>](fg:yellow) go back
+  [<q>](fg:yellow) quit`
+	profilesUsage = `  [<Up>](fg:yellow)/[<Down>](fg:yellow)   move selection up/down   [<Enter>](fg:yellow) switch profile
+[<PgUp>](fg:yellow)/[<PgDown>](fg:yellow) scroll up/down           [<Esc>](fg:yellow)   go back
+[<Home>](fg:yellow)/[<End>](fg:yellow)    move to top/bottom       [<q>](fg:yellow)     quit`
+	formUsage = `[<Tab>](fg:yellow)/[<Up>](fg:yellow)/[<Down>](fg:yellow) move between fields   [<Esc>](fg:yellow) cancel
+              [<Enter>](fg:yellow) submit`
+	oneShotUsage = `[<Esc>](fg:yellow) cancel scan
+  [<q>](fg:yellow) quit`
+	promptUsage = `[<Enter>](fg:yellow) submit
+  [<Esc>](fg:yellow) cancel`
+)